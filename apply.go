@@ -0,0 +1,202 @@
+package pindxru
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ChangeReport Итоги применения пакетов изменений.
+type ChangeReport struct {
+	// Дата последнего примененного пакета
+	Date time.Time
+	// Новые объекты почтовой связи
+	Added []string
+	// Объекты, у которых изменились реквизиты
+	Modified []string
+	// Объекты, у которых изменился индекс: старый индекс -> новый индекс
+	Renumbered map[string]string
+	// Закрытые объекты
+	Closed []string
+	// Все примененные изменения по порядку
+	Changes []Change
+	// Записи пакетов, которые не изменили справочник: реквизиты уже актуальны или закрываемого объекта нет
+	Skipped int
+}
+
+// Apply Применяет пакеты изменений к снимку справочника snapshot и возвращает новый снимок.
+// snapshot не изменяется.
+//
+// Пакеты применяются в порядке дат, у пакетов должны быть загружены индексы (см. GetPackageIndexes).
+// Запись пакета обрабатывается в зависимости от вида изменения (см. NewChange):
+//   - ChangeClosed - объект удаляется;
+//   - ChangeAdded, ChangeModified - объект добавляется или его реквизиты заменяются;
+//   - ChangeRenumbered - объект переходит на новый индекс. У подчиненных объектов,
+//     которых нет в пакете, OpsSub заменяется на новый индекс.
+//
+// Новые объекты добавляются в конец снимка, порядок остальных сохраняется.
+func Apply(snapshot []PIndx, pkgs ...Package) (indexes []PIndx, report ChangeReport, err error) {
+	sorted := append([]Package{}, pkgs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	if err = checkLoaded(sorted); err != nil {
+		return
+	}
+
+	s := newSnapshot(snapshot)
+	report.Renumbered = map[string]string{}
+	for _, pack := range sorted {
+		s.apply(pack, &report)
+		report.Date = pack.Date
+	}
+
+	indexes = s.indexes()
+	return
+}
+
+// checkLoaded Возвращает ErrPackageNotLoaded, если у пакета с записями не загружены индексы.
+func checkLoaded(pkgs []Package) (err error) {
+	for _, pack := range pkgs {
+		if len(pack.Indexes) == 0 && pack.NumberRecords > 0 {
+			err = fmt.Errorf("%w: %s", ErrPackageNotLoaded, pack.Url)
+			return
+		}
+	}
+	return
+}
+
+// snapshot Снимок справочника с поиском по индексу.
+type snapshot struct {
+	items   []PIndx
+	deleted []bool
+	pos     map[string]int
+}
+
+func newSnapshot(indexes []PIndx) (s *snapshot) {
+	s = &snapshot{
+		items:   append([]PIndx{}, indexes...),
+		deleted: make([]bool, len(indexes)),
+		pos:     make(map[string]int, len(indexes)),
+	}
+
+	for i, p := range s.items {
+		s.pos[p.Index] = i
+	}
+	return
+}
+
+func (s *snapshot) get(index string) (p *PIndx, ok bool) {
+	var i int
+	if i, ok = s.pos[index]; ok {
+		p = &s.items[i]
+	}
+	return
+}
+
+func (s *snapshot) set(p PIndx) {
+	if i, ok := s.pos[p.Index]; ok {
+		s.items[i] = p
+		return
+	}
+
+	s.pos[p.Index] = len(s.items)
+	s.items = append(s.items, p)
+	s.deleted = append(s.deleted, false)
+}
+
+func (s *snapshot) remove(index string) (ok bool) {
+	var i int
+	if i, ok = s.pos[index]; ok {
+		s.deleted[i] = true
+		delete(s.pos, index)
+	}
+	return
+}
+
+func (s *snapshot) indexes() (indexes []PIndx) {
+	indexes = make([]PIndx, 0, len(s.pos))
+	for i, p := range s.items {
+		if !s.deleted[i] {
+			indexes = append(indexes, p)
+		}
+	}
+	return
+}
+
+// apply Применяет записи пакета.
+func (s *snapshot) apply(pack Package, report *ChangeReport) {
+	inPackage := map[string]bool{}
+	renumbered := map[string]string{}
+
+	for _, np := range pack.Indexes {
+		inPackage[np.Index] = true
+		inPackage[np.NewIndex] = true
+
+		before, _ := s.get(np.Index)
+		ch := NewChange(np, before)
+		switch ch.Kind {
+		case ChangeClosed:
+			if !s.remove(np.Index) {
+				report.Skipped++
+				continue
+			}
+			report.Closed = append(report.Closed, np.Index)
+
+		case ChangeRenumbered:
+			renumbered[np.Index] = np.NewIndex
+			if current, ok := s.get(np.NewIndex); ok && before == nil && len(diffPIndx(*current, *ch.After)) == 0 {
+				// пакет уже применен
+				report.Skipped++
+				continue
+			}
+
+			s.remove(np.Index)
+			s.set(*ch.After)
+			report.Renumbered[np.Index] = np.NewIndex
+
+		case ChangeAdded:
+			s.set(*ch.After)
+			report.Added = append(report.Added, np.Index)
+
+		case ChangeModified:
+			if len(ch.Diff) == 0 {
+				report.Skipped++
+				continue
+			}
+			s.set(*ch.After)
+			report.Modified = append(report.Modified, np.Index)
+		}
+		report.Changes = append(report.Changes, ch)
+	}
+
+	if len(renumbered) == 0 {
+		return
+	}
+
+	for i := range s.items {
+		p := &s.items[i]
+		if newIndex, ok := renumbered[p.OpsSub]; ok && !s.deleted[i] && !inPackage[p.Index] {
+			p.OpsSub = newIndex
+		}
+	}
+}
+
+// pindx Возвращает запись справочника после изменения.
+func (p NPIndx) pindx() PIndx {
+	return PIndx{
+		Index:      p.NewIndex,
+		OpsName:    p.OpsName,
+		OpsType:    p.OpsType,
+		OpsSub:     p.OpsSub,
+		Region:     p.Region,
+		Autonomy:   p.Autonomy,
+		Area:       p.Area,
+		City:       p.City,
+		SubCity:    p.SubCity,
+		UpdatedAt:  p.UpdatedAt,
+		OldIndex:   p.OldIndex,
+		RegionCode: p.RegionCode,
+	}
+}
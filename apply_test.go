@@ -0,0 +1,94 @@
+package pindxru
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testReadZip Читает записи архива из testdata.
+func testReadZip[T any](t *testing.T, name string, read func(io.ReaderAt, int64) ([]T, error)) []T {
+	b, err := os.ReadFile(filepath.Join(testdata, name))
+	require.Nil(t, err)
+	items, err := read(bytes.NewReader(b), int64(len(b)))
+	require.Nil(t, err)
+	return items
+}
+
+func TestApply(t *testing.T) {
+	snapshot := testReadZip(t, testPIndxZip, ReadPIndxZip)
+	updates := testReadZip(t, testNPIndxZip, ReadNPIndxZip)
+
+	closing := NPIndx{Index: "628001", Region: "ТЮМЕНСКАЯ ОБЛАСТЬ"}
+	renumbering := NPIndx{Index: "664000", NewIndex: "664010"}
+	renumbering.OpsName = "ИРКУТСК ПОЧТАМТ"
+	renumbering.OpsType = "ПОЧТАМТ"
+	renumbering.OpsSub = "664700"
+
+	pkgs := []Package{
+		{Date: time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC), Indexes: []NPIndx{closing, renumbering}},
+		{Date: time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC), Indexes: updates},
+	}
+
+	indexes, report, err := Apply(snapshot, pkgs...)
+	require.Nil(t, err)
+	require.Len(t, snapshot, len(testPIndxRows))
+	require.Equal(t, time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC), report.Date)
+	require.Equal(t, []string{"664099"}, report.Added)
+	require.Equal(t, []string{"664001"}, report.Modified)
+	require.Equal(t, map[string]string{"664520": "664521", "664000": "664010"}, report.Renumbered)
+	require.Equal(t, []string{"628001"}, report.Closed)
+	require.Equal(t, 0, report.Skipped)
+	require.Len(t, report.Changes, 5)
+	require.Equal(t, ChangeModified, report.Changes[0].Kind)
+	require.Equal(t, ChangeClosed, report.Changes[3].Kind)
+
+	byIndex := map[string]PIndx{}
+	for _, p := range indexes {
+		byIndex[p.Index] = p
+	}
+	require.Len(t, byIndex, len(indexes))
+	require.Len(t, indexes, 5)
+	require.Equal(t, "ИРКУТСК 1 ОПС", byIndex["664001"].OpsName)
+	require.Equal(t, "МАРКОВА", byIndex["664521"].OpsName)
+	require.NotContains(t, byIndex, "664520")
+	require.NotContains(t, byIndex, "628001")
+	require.NotContains(t, byIndex, "664000")
+
+	// подчиненные объекты переходят на новый индекс вышестоящего
+	require.Equal(t, "664010", byIndex["664001"].OpsSub)
+	require.Equal(t, "664010", byIndex["664521"].OpsSub)
+	require.Equal(t, "664010", byIndex["664099"].OpsSub)
+
+	// повторное применение ничего не меняет
+	applied, report, err := Apply(snapshot, pkgs[1])
+	require.Nil(t, err)
+	require.Len(t, report.Added, 1)
+	require.Len(t, report.Modified, 1)
+	require.Len(t, report.Renumbered, 1)
+
+	again, report, err := Apply(applied, pkgs[1])
+	require.Nil(t, err)
+	require.Equal(t, applied, again)
+	require.Empty(t, report.Added)
+	require.Empty(t, report.Modified)
+	require.Empty(t, report.Renumbered)
+	require.Equal(t, len(updates), report.Skipped)
+
+	_, _, err = Apply(snapshot, Package{Url: "NPIndx01.zip", NumberRecords: 3})
+	require.ErrorIs(t, err, ErrPackageNotLoaded)
+
+	// пакеты без вызова GetPackageIndexes
+	_, rows := newTestClient(t)
+	unloaded, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+	require.NotEmpty(t, unloaded)
+	_, report, err = Apply(snapshot, unloaded...)
+	require.ErrorIs(t, err, ErrPackageNotLoaded)
+	require.True(t, report.Date.IsZero())
+}
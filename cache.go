@@ -0,0 +1,256 @@
+package pindxru
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// httpCache Кэш ответов на диске.
+//
+// Для каждого URL хранится файл `<sha256(URL)>.json` с ETag, Last-Modified и контрольной суммой
+// тела ответа, а само тело - в файле `<sha256(URL)>-<sha256(тела)>`.
+type httpCache struct {
+	dir string
+}
+
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+func (h *httpCache) key(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *httpCache) metaPath(u string) string {
+	return filepath.Join(h.dir, h.key(u)+".json")
+}
+
+func (h *httpCache) bodyPath(u, checksum string) string {
+	return filepath.Join(h.dir, h.key(u)+"-"+checksum)
+}
+
+// load Возвращает запись кэша для u. Запись с поврежденным телом удаляется.
+func (h *httpCache) load(u string) (e *cacheEntry, ok bool) {
+	b, err := os.ReadFile(h.metaPath(u))
+	if err != nil {
+		return
+	}
+
+	e = &cacheEntry{}
+	if err = json.Unmarshal(b, e); err != nil || e.URL != u {
+		return nil, false
+	}
+
+	if checksum, err := fileChecksum(h.bodyPath(u, e.SHA256)); err != nil || checksum != e.SHA256 {
+		h.remove(u, e)
+		return nil, false
+	}
+
+	ok = true
+	return
+}
+
+func (h *httpCache) remove(u string, e *cacheEntry) {
+	_ = os.Remove(h.metaPath(u))
+	_ = os.Remove(h.bodyPath(u, e.SHA256))
+}
+
+// prepare Добавляет в запрос условные заголовки, если ответ есть в кэше.
+// Запросы части файла (Range) выполняются без условий.
+func (h *httpCache) prepare(req *http.Request) {
+	if req.Header.Get("Range") != "" {
+		return
+	}
+
+	e, ok := h.load(req.URL.String())
+	if !ok {
+		return
+	}
+
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// response Заменяет ответ 304 Not Modified ответом из кэша.
+func (h *httpCache) response(notModified *http.Response) (resp *http.Response, err error) {
+	_ = notModified.Body.Close()
+
+	u := notModified.Request.URL.String()
+	e, ok := h.load(u)
+	if !ok {
+		err = &HTTPError{StatusCode: notModified.StatusCode, URL: u}
+		return
+	}
+
+	var f *os.File
+	if f, err = os.Open(h.bodyPath(u, e.SHA256)); err != nil {
+		return
+	}
+
+	var fi os.FileInfo
+	if fi, err = f.Stat(); err != nil {
+		_ = f.Close()
+		return
+	}
+
+	resp = &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        notModified.Header.Clone(),
+		Body:          f,
+		ContentLength: fi.Size(),
+		Request:       notModified.Request,
+	}
+
+	if e.ContentType != "" {
+		resp.Header.Set("Content-Type", e.ContentType)
+	}
+	return
+}
+
+// writer Возвращает обертку тела ответа, которая сохраняет его в кэш.
+// Ответы без ETag и Last-Modified не кэшируются, в этом случае возвращается nil.
+func (h *httpCache) writer(resp *http.Response) (w *cacheWriter, err error) {
+	e := &cacheEntry{
+		URL:          resp.Request.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+	}
+
+	if e.ETag == "" && e.LastModified == "" {
+		return
+	}
+
+	if err = os.MkdirAll(h.dir, 0755); err != nil {
+		return
+	}
+
+	var f *os.File
+	if f, err = os.CreateTemp(h.dir, "tmp-*"); err != nil {
+		return
+	}
+
+	w = &cacheWriter{
+		cache: h,
+		entry: e,
+		body:  resp.Body,
+		f:     f,
+		hash:  sha256.New(),
+	}
+	return
+}
+
+// cacheWriter Сохраняет прочитанное тело ответа во временный файл кэша.
+type cacheWriter struct {
+	cache *httpCache
+	entry *cacheEntry
+	body  io.ReadCloser
+	f     *os.File
+	hash  hash.Hash
+	err   error
+	eof   bool
+}
+
+func (w *cacheWriter) Read(p []byte) (n int, err error) {
+	n, err = w.body.Read(p)
+	if n > 0 && w.err == nil {
+		if _, w.err = w.f.Write(p[:n]); w.err == nil {
+			w.hash.Write(p[:n])
+		}
+	}
+
+	if err == io.EOF {
+		w.eof = true
+	}
+	return
+}
+
+func (w *cacheWriter) Close() error {
+	return w.body.Close()
+}
+
+// commit Сохраняет запись в кэш, если тело ответа прочитано полностью.
+func (w *cacheWriter) commit() (err error) {
+	if !w.eof || w.err != nil {
+		w.abort()
+		return
+	}
+
+	if err = w.f.Close(); err != nil {
+		_ = os.Remove(w.f.Name())
+		return
+	}
+
+	u := w.entry.URL
+	old, hasOld := w.cache.load(u)
+
+	w.entry.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+	if err = os.Rename(w.f.Name(), w.cache.bodyPath(u, w.entry.SHA256)); err != nil {
+		_ = os.Remove(w.f.Name())
+		return
+	}
+
+	var b []byte
+	if b, err = json.Marshal(w.entry); err == nil {
+		err = os.WriteFile(w.cache.metaPath(u), b, 0644)
+	}
+
+	if err != nil {
+		if !hasOld || old.SHA256 != w.entry.SHA256 {
+			_ = os.Remove(w.cache.bodyPath(u, w.entry.SHA256))
+		}
+		return
+	}
+
+	if hasOld && old.SHA256 != w.entry.SHA256 {
+		_ = os.Remove(w.cache.bodyPath(u, old.SHA256))
+	}
+	return
+}
+
+// abort Удаляет временный файл.
+func (w *cacheWriter) abort() {
+	_ = w.f.Close()
+	_ = os.Remove(w.f.Name())
+}
+
+// fileChecksum Возвращает SHA-256 содержимого файла.
+func fileChecksum(filename string) (checksum string, err error) {
+	var f *os.File
+	if f, err = os.Open(filename); err != nil {
+		return
+	}
+
+	defer func() {
+		if derr := f.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return
+	}
+	checksum = hex.EncodeToString(h.Sum(nil))
+	return
+}
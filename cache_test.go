@@ -0,0 +1,108 @@
+package pindxru
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithCache(t *testing.T) {
+	content := dbftest.MakeZip("NPIndx01.dbf", dbftest.MakeDbf(dbftest.CharFields(6), nil))
+	var statuses []int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("ETag", `"v1"`)
+		http.ServeContent(rec, r, "NPIndx01.zip", time.Time{}, bytes.NewReader(content))
+		statuses = append(statuses, rec.Code)
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	c := NewClient(nil, WithCache(dir))
+	u := ts.URL + "/NPIndx01.zip"
+
+	download := func() []byte {
+		f, err := c.downloadZip(context.Background(), u)
+		require.Nil(t, err)
+		defer removeTempFile(f)
+
+		b, err := os.ReadFile(f.Name())
+		require.Nil(t, err)
+		return b
+	}
+
+	require.Equal(t, content, download())
+	require.Equal(t, content, download())
+	require.Equal(t, []int{http.StatusOK, http.StatusNotModified}, statuses)
+
+	// поврежденный кэш не используется
+	e, ok := c.cache.load(u)
+	require.True(t, ok)
+	require.Nil(t, os.WriteFile(c.cache.bodyPath(u, e.SHA256), []byte("broken"), 0644))
+
+	statuses = nil
+	require.Equal(t, content, download())
+	require.Equal(t, []int{http.StatusOK}, statuses)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.Nil(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestClient_WithCacheWriteError(t *testing.T) {
+	content := dbftest.MakeZip("NPIndx01.dbf", dbftest.MakeDbf(dbftest.CharFields(6), nil))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "NPIndx01.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+
+	u := ts.URL + "/NPIndx01.zip"
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	download := func(c *Client) {
+		f, err := c.downloadZip(context.Background(), u)
+		require.Nil(t, err)
+		defer removeTempFile(f)
+
+		b, err := os.ReadFile(f.Name())
+		require.Nil(t, err)
+		require.Equal(t, content, b)
+	}
+
+	// каталог кэша не создается
+	file := filepath.Join(t.TempDir(), "cache")
+	require.Nil(t, os.WriteFile(file, nil, 0644))
+	download(NewClient(nil, WithCache(file)))
+
+	// метаданные не записываются
+	dir := t.TempDir()
+	c := NewClient(nil, WithCache(dir))
+	require.Nil(t, os.Mkdir(c.cache.metaPath(u), 0755))
+	download(c)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.Nil(t, err)
+	require.Equal(t, []string{c.cache.metaPath(u)}, files)
+
+	files, err = filepath.Glob(filepath.Join(tmp, "pindxru-*.zip"))
+	require.Nil(t, err)
+	require.Empty(t, files)
+}
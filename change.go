@@ -0,0 +1,142 @@
+package pindxru
+
+import (
+	"strconv"
+)
+
+// ChangeKind Вид изменения объекта почтовой связи.
+type ChangeKind int
+
+const (
+	// ChangeAdded новый объект.
+	ChangeAdded ChangeKind = iota + 1
+	// ChangeModified изменились реквизиты объекта.
+	ChangeModified
+	// ChangeRenumbered объект перешел на новый индекс, реквизиты также могли измениться.
+	ChangeRenumbered
+	// ChangeClosed объект закрыт.
+	ChangeClosed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeModified:
+		return "modified"
+	case ChangeRenumbered:
+		return "renumbered"
+	case ChangeClosed:
+		return "closed"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// FieldDiff Изменение поля PIndx.
+type FieldDiff struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// Change Изменение объекта почтовой связи из пакета изменений.
+type Change struct {
+	Kind ChangeKind
+	// Индекс объекта до изменения
+	Index string
+	// Индекс объекта после изменения, пустой для закрытых объектов
+	NewIndex string
+	// Запись до изменения, nil, если запись не известна
+	Before *PIndx
+	// Запись после изменения, nil для закрытых объектов
+	After *PIndx
+	// Изменившиеся поля. Если запись до изменения не известна, то все заполненные поля.
+	Diff []FieldDiff
+}
+
+// NewChange Определяет вид изменения по записи пакета np и текущей записи справочника before.
+//
+// Если before не указана, то запись с совпадающими Index и NewIndex считается новым объектом.
+// Пустой NewIndex означает закрытие объекта.
+func NewChange(np NPIndx, before *PIndx) (ch Change) {
+	ch = Change{
+		Index:    np.Index,
+		NewIndex: np.NewIndex,
+	}
+
+	var b, a PIndx
+	if before != nil {
+		b = *before
+		ch.Before = &b
+	}
+
+	switch {
+	case np.NewIndex == "":
+		ch.Kind = ChangeClosed
+	case np.NewIndex != np.Index:
+		ch.Kind = ChangeRenumbered
+	case before == nil:
+		ch.Kind = ChangeAdded
+	default:
+		ch.Kind = ChangeModified
+	}
+
+	if ch.Kind != ChangeClosed {
+		a = np.pindx()
+		ch.After = &a
+	}
+
+	ch.Diff = diffPIndx(b, a)
+	return
+}
+
+// Changes Возвращает изменения пакета. Текущие записи берутся из snapshot, который может быть nil.
+func (p Package) Changes(snapshot []PIndx) (changes []Change) {
+	byIndex := make(map[string]*PIndx, len(snapshot))
+	for i := range snapshot {
+		byIndex[snapshot[i].Index] = &snapshot[i]
+	}
+
+	changes = make([]Change, 0, len(p.Indexes))
+	for _, np := range p.Indexes {
+		changes = append(changes, NewChange(np, byIndex[np.Index]))
+	}
+	return
+}
+
+// diffPIndx Возвращает различающиеся поля записей.
+func diffPIndx(before, after PIndx) (diff []FieldDiff) {
+	date := func(t PIndx) string {
+		if t.UpdatedAt.IsZero() {
+			return ""
+		}
+		return t.UpdatedAt.Format("2006-01-02")
+	}
+
+	code := func(t PIndx) string {
+		if t.RegionCode == 0 {
+			return ""
+		}
+		return strconv.Itoa(t.RegionCode)
+	}
+
+	for _, f := range []FieldDiff{
+		{"Index", before.Index, after.Index},
+		{"OpsName", before.OpsName, after.OpsName},
+		{"OpsType", before.OpsType, after.OpsType},
+		{"OpsSub", before.OpsSub, after.OpsSub},
+		{"Region", before.Region, after.Region},
+		{"Autonomy", before.Autonomy, after.Autonomy},
+		{"Area", before.Area, after.Area},
+		{"City", before.City, after.City},
+		{"SubCity", before.SubCity, after.SubCity},
+		{"UpdatedAt", date(before), date(after)},
+		{"OldIndex", before.OldIndex, after.OldIndex},
+		{"RegionCode", code(before), code(after)},
+	} {
+		if f.Before != f.After {
+			diff = append(diff, f)
+		}
+	}
+	return
+}
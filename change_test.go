@@ -0,0 +1,55 @@
+package pindxru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackage_Changes(t *testing.T) {
+	snapshot := testReadZip(t, testPIndxZip, ReadPIndxZip)
+	pack := Package{
+		Date:    time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC),
+		Indexes: testReadZip(t, testNPIndxZip, ReadNPIndxZip),
+	}
+
+	changes := pack.Changes(snapshot)
+	require.Len(t, changes, 3)
+
+	require.Equal(t, ChangeModified, changes[0].Kind)
+	require.Equal(t, "664001", changes[0].Index)
+	require.Equal(t, snapshot[2], *changes[0].Before)
+	require.Equal(t, []FieldDiff{
+		{Field: "OpsName", Before: "ИРКУТСК 1", After: "ИРКУТСК 1 ОПС"},
+		{Field: "UpdatedAt", Before: "2021-01-01", After: "2021-01-15"},
+	}, changes[0].Diff)
+
+	require.Equal(t, ChangeRenumbered, changes[1].Kind)
+	require.Equal(t, "664521", changes[1].NewIndex)
+	require.Equal(t, "664521", changes[1].After.Index)
+	require.Equal(t, []FieldDiff{
+		{Field: "Index", Before: "664520", After: "664521"},
+		{Field: "UpdatedAt", Before: "2021-01-01", After: "2021-01-18"},
+	}, changes[1].Diff)
+
+	require.Equal(t, ChangeAdded, changes[2].Kind)
+	require.Nil(t, changes[2].Before)
+	require.Contains(t, changes[2].Diff, FieldDiff{Field: "OpsName", After: "ИРКУТСК 99"})
+
+	// без снимка справочника
+	changes = pack.Changes(nil)
+	require.Equal(t, ChangeAdded, changes[0].Kind)
+	require.Equal(t, ChangeRenumbered, changes[1].Kind)
+
+	closed := NewChange(NPIndx{Index: "628001"}, &snapshot[4])
+	require.Equal(t, ChangeClosed, closed.Kind)
+	require.Nil(t, closed.After)
+	require.Contains(t, closed.Diff, FieldDiff{Field: "OpsName", Before: "ХАНТЫ-МАНСИЙСК 1"})
+	require.Equal(t, "closed", closed.Kind.String())
+	require.Equal(t, "ChangeKind(0)", ChangeKind(0).String())
+
+	// запись до изменения копируется
+	snapshot[4].OpsName = "-"
+	require.Equal(t, "ХАНТЫ-МАНСИЙСК 1", closed.Before.OpsName)
+}
@@ -2,280 +2,598 @@ package pindxru
 
 import (
 	"archive/zip"
-	"bytes"
-	"errors"
+	"context"
+	"io"
 	"net/http"
 	"os"
 	"regexp"
-	"strconv"
 	"time"
 
-	"github.com/NovikovRoman/godbf"
 	"golang.org/x/text/encoding/charmap"
 )
 
 const (
-	rootURL        = "https://www.pochta.ru"
-	listUpdatesURL = rootURL + "/support/database/ops"
+	rootURL         = "https://www.pochta.ru"
+	listUpdatesPath = "/support/database/ops"
 )
 
-var fileEncoding = charmap.CodePage866
+var (
+	fileEncoding = charmap.CodePage866
+	dbfNameRe    = regexp.MustCompile(`(?si)^(PIndx|NPIndx)\d*\.dbf$`)
+)
 
 // Client structure.
 type Client struct {
 	httpClient *http.Client
 	transport  *http.Transport
+	baseURL    string
+	userAgent  string
+	headers    http.Header
+	timeout    time.Duration
+	retry      RetryPolicy
+	cache      *httpCache
+	onProgress ProgressFunc
+	source     ReferenceSource
 }
 
 // NewClient create new pindxru Client.
-func NewClient(transport *http.Transport) *Client {
-	c := &http.Client{}
+func NewClient(transport *http.Transport, opts ...Option) *Client {
+	hc := &http.Client{}
 	if transport != nil {
-		c.Transport = transport
+		hc.Transport = transport
 	}
 
-	return &Client{
-		httpClient: c,
+	c := &Client{
+		httpClient: hc,
 		transport:  transport,
+		baseURL:    rootURL,
+		headers:    http.Header{},
 	}
-}
 
-func (c *Client) GetReferenceRows() (referenceRows ReferenceRows, err error) {
-	var b []byte
-	if b, err = c.loadPage(); err != nil {
-		return
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	referenceRows, err = c.parseReferenceRows(b)
-	return
+	if c.timeout > 0 {
+		// копия, чтобы не изменять http-клиент, переданный через WithHTTPClient
+		hc := *c.httpClient
+		hc.Timeout = c.timeout
+		c.httpClient = &hc
+	}
+
+	return c
 }
 
-func (c *Client) parseReferenceRows(b []byte) (referenceRows ReferenceRows, err error) {
-	content := regexp.MustCompile(`(?si)<table[^>]*>.+?<td[^>]*>Обновленный эталонный справочник.+?</tr>(.+?)</table>`).FindSubmatch(b)
-	if len(content) == 0 {
-		err = errors.New("Не найден контент. ")
-		return
-	}
-
-	re := regexp.MustCompile(`(?si)<tr[^>]*><td[^>]*>\s*(\d{2}\.\d{2}\.\d{4})\s*.+?<td[^>]*>\s*(\d+)\s*.+?<td[^>]*>.+?href="(.+?)".+?</a>.+?(\d+)(?:\s+|&nbsp;| )запис.+?href="(.+?)".+?</a>.+?(\d+)(?:\s+|&nbsp;| )запис`)
-	rows := re.FindAllSubmatch(content[1], -1)
-	referenceRows = make([]ReferenceRow, len(rows))
-	for i, r := range rows {
-		referenceRows[i] = ReferenceRow{
-			Number: string(r[2]),
-			Update: ReferenceFile{
-				Url: rootURL + string(r[3]),
-			},
-			Full: ReferenceFile{
-				Url: rootURL + string(r[5]),
-			},
-		}
-		referenceRows[i].Date, _ = time.Parse("02.01.2006", string(r[1]))
-		referenceRows[i].Update.Records, _ = strconv.Atoi(string(r[4]))
-		referenceRows[i].Full.Records, _ = strconv.Atoi(string(r[6]))
+// GetReferenceRows Возвращает строки web-справочника.
+func (c *Client) GetReferenceRows() (referenceRows ReferenceRows, err error) {
+	return c.GetReferenceRowsContext(context.Background())
+}
+
+// GetReferenceRowsContext то же, что и GetReferenceRows, но с контекстом.
+// Если задан источник WithSource, то строки возвращает он, иначе строки берутся со страницы web-справочника.
+func (c *Client) GetReferenceRowsContext(ctx context.Context) (referenceRows ReferenceRows, err error) {
+	source := c.source
+	if source == nil {
+		source = NewPageSource(c)
 	}
-	return
+	return source.ReferenceRows(ctx)
 }
 
 // Indexes Возвращает все почтовые индексы из web-справочника.
 func (c *Client) Indexes(referenceRows ReferenceRows, lastModified *time.Time) (indexes []PIndx, lastMod time.Time, err error) {
+	return c.IndexesContext(context.Background(), referenceRows, lastModified)
+}
+
+// IndexesContext то же, что и Indexes, но с контекстом.
+//
+// Количество разобранных записей сверяется с Full.Records строки, при расхождении возвращается
+// RecordCountError. Контрольная сумма архива сверяется с Full.SHA256, если она указана,
+// и после разбора записывается в Full.SHA256 строки referenceRows.
+func (c *Client) IndexesContext(ctx context.Context, referenceRows ReferenceRows, lastModified *time.Time) (indexes []PIndx, lastMod time.Time, err error) {
 	var (
-		b  []byte
-		ok bool
+		f   *os.File
+		row *ReferenceRow
+		sum string
 	)
 
-	if len(referenceRows) == 0 {
+	if f, row, sum, err = c.getFullZip(ctx, referenceRows, lastModified); err != nil || row == nil {
 		return
 	}
+	defer removeTempFile(f)
 
-	if lastModified != nil {
-		if ok, err = referenceRows.hasUpdates(*lastModified); err != nil || !ok {
-			return
-		}
+	if indexes, err = c.unzipPIndex(ctx, f, row.Full); err != nil {
+		return
 	}
+	lastMod = row.Date
+	row.Full.SHA256 = sum
+	return
+}
+
+// EachIndex Последовательно передает в fn все почтовые индексы из web-справочника,
+// не загружая их в память целиком.
+//
+// Архив предварительно загружается во временный файл. Если fn возвращает ошибку,
+// разбор прекращается и эта ошибка возвращается.
+//
+// Количество записей в заголовке dbf-файла сверяется с Full.Records до вызова fn,
+// а количество разобранных записей - после. При расхождении возвращается RecordCountError,
+// и все переданные в fn индексы нужно отбросить.
+func (c *Client) EachIndex(ctx context.Context, referenceRows ReferenceRows, fn func(PIndx) error) (lastMod time.Time, err error) {
+	var (
+		f   *os.File
+		row *ReferenceRow
+		sum string
+	)
 
-	lastRow, _ := referenceRows.LastRow()
-	if b, err = c.downloadZip(lastRow.Full.Url); err != nil {
+	if f, row, sum, err = c.getFullZip(ctx, referenceRows, nil); err != nil || row == nil {
 		return
 	}
+	defer removeTempFile(f)
 
-	lastMod = lastRow.Date
-	indexes, err = c.unzipPIndex(b)
+	if err = c.eachPIndx(ctx, f, row.Full, fn); err != nil {
+		return
+	}
+	lastMod = row.Date
+	row.Full.SHA256 = sum
 	return
 }
 
 // IndexesZip Загружает zip-файл со всеми почтовыми индексами.
 func (c Client) IndexesZip(referenceRows ReferenceRows, fname string, perm os.FileMode, lastMod *time.Time) (modify time.Time, ok bool, err error) {
-	var b []byte
-	if b, modify, ok, err = c.getFullZip(referenceRows, lastMod); err != nil || !ok {
+	return c.IndexesZipContext(context.Background(), referenceRows, fname, perm, lastMod)
+}
+
+// IndexesZipContext то же, что и IndexesZip, но с контекстом.
+//
+// Если загрузка прервалась, то при повторном вызове файл докачивается (см. DownloadTo).
+func (c Client) IndexesZipContext(ctx context.Context, referenceRows ReferenceRows, fname string, perm os.FileMode, lastMod *time.Time) (modify time.Time, ok bool, err error) {
+	var lastRow *ReferenceRow
+	if lastRow, ok, err = referenceRows.lastUpdate(lastMod); err != nil || !ok {
 		return
 	}
 
-	if len(b) > 0 {
-		err = os.WriteFile(fname, b, perm)
+	var sum string
+	if sum, err = c.downloadTo(ctx, lastRow.Full.Url, fname, perm, lastRow.Full.SHA256); err != nil {
+		ok = false
+		return
 	}
 
-	ok = err == nil
+	modify = lastRow.Date
+	lastRow.Full.SHA256 = sum
 	return
 }
 
 // IndexesDbf Загружает dbf-файл со всеми почтовыми индексами.
 func (c Client) IndexesDbf(referenceRows ReferenceRows, fname string, perm os.FileMode, lastMod *time.Time) (modify time.Time, ok bool, err error) {
-	var b []byte
-	if b, modify, ok, err = c.getFullZip(referenceRows, lastMod); err != nil || !ok {
-		return
-	}
+	return c.IndexesDbfContext(context.Background(), referenceRows, fname, perm, lastMod)
+}
+
+// IndexesDbfContext то же, что и IndexesDbf, но с контекстом.
+func (c Client) IndexesDbfContext(ctx context.Context, referenceRows ReferenceRows, fname string, perm os.FileMode, lastMod *time.Time) (modify time.Time, ok bool, err error) {
+	var (
+		f   *os.File
+		row *ReferenceRow
+		sum string
+	)
 
-	if b, err = c.unzipDbf(b); err != nil {
+	if f, row, sum, err = c.getFullZip(ctx, referenceRows, lastMod); err != nil || row == nil {
 		return
 	}
+	defer removeTempFile(f)
 
-	if len(b) > 0 {
-		err = os.WriteFile(fname, b, perm)
+	if err = c.extractDbf(f, fname, perm); err != nil {
+		return
 	}
 
-	ok = err == nil
+	ok = true
+	modify = row.Date
+	row.Full.SHA256 = sum
 	return
 }
 
-// getFullZip Возвращает последнее полное обновление.
+// getFullZip Загружает во временный файл последнее полное обновление.
+// Временный файл необходимо удалить с помощью removeTempFile.
 //
 // Если не указана lastMod, то самая последняя запись.
 //
 // Если lastMod указана, то если есть запись после указаной даты.
-func (c *Client) getFullZip(referenceRows ReferenceRows, lastMod *time.Time) (b []byte, modify time.Time, ok bool, err error) {
-	if len(referenceRows) == 0 {
+// Если обновлений нет, то возвращает row == nil.
+//
+// Контрольная сумма архива sum сверяется с Full.SHA256 строки, если она указана.
+func (c *Client) getFullZip(ctx context.Context, referenceRows ReferenceRows, lastMod *time.Time) (f *os.File, row *ReferenceRow, sum string, err error) {
+	var (
+		lastRow *ReferenceRow
+		ok      bool
+	)
+
+	if lastRow, ok, err = referenceRows.lastUpdate(lastMod); err != nil || !ok {
 		return
 	}
 
-	if lastMod != nil {
-		if ok, err = referenceRows.hasUpdates(*lastMod); err != nil || !ok {
-			return
-		}
+	if f, err = c.downloadZip(ctx, lastRow.Full.Url); err != nil {
+		return
 	}
 
-	ok = true
-	lastRow, _ := referenceRows.LastRow()
-	modify = lastRow.Date
-	b, err = c.downloadZip(lastRow.Full.Url)
+	if sum, err = verifyChecksum(lastRow.Full.Url, f.Name(), lastRow.Full.SHA256); err != nil {
+		removeTempFile(f)
+		f = nil
+		return
+	}
+
+	row = lastRow
 	return
 }
 
 // GetPackageIndexes получает изменения.
 func (c Client) GetPackageIndexes(pack *Package) (lastMod time.Time, err error) {
-	var b []byte
-	if b, err = c.downloadZip(pack.Url); err != nil {
+	return c.GetPackageIndexesContext(context.Background(), pack)
+}
+
+// GetPackageIndexesContext то же, что и GetPackageIndexes, но с контекстом.
+//
+// Контрольная сумма архива сверяется с pack.SHA256, если она указана, и после разбора записывается в pack.SHA256.
+// Количество записей сверяется с pack.NumberRecords.
+func (c Client) GetPackageIndexesContext(ctx context.Context, pack *Package) (lastMod time.Time, err error) {
+	var (
+		f   *os.File
+		sum string
+	)
+
+	if f, sum, err = c.downloadPackage(ctx, *pack); err != nil {
 		return
 	}
+	defer removeTempFile(f)
 
-	pack.Indexes, lastMod, err = c.unzipNPIndx(b)
+	if pack.Indexes, lastMod, err = c.unzipNPIndx(ctx, f, pack.file()); err != nil {
+		return
+	}
+	pack.SHA256 = sum
 	return
 }
 
-// PackageZip загружает zip-файл пакета изменений.
-func (c Client) PackageZip(pack Package, filename string, perm os.FileMode) (err error) {
-	var b []byte
-	if b, err = c.downloadZip(pack.Url); err != nil {
+// EachPackageIndex Последовательно передает в fn изменения из пакета, не загружая их в память целиком.
+//
+// Если fn возвращает ошибку, разбор прекращается и эта ошибка возвращается.
+// Количество записей проверяется так же, как в EachIndex.
+func (c Client) EachPackageIndex(ctx context.Context, pack Package, fn func(NPIndx) error) (lastMod time.Time, err error) {
+	var f *os.File
+	if f, _, err = c.downloadPackage(ctx, pack); err != nil {
 		return
 	}
-	err = os.WriteFile(filename, b, perm)
+	defer removeTempFile(f)
+
+	lastMod, err = c.eachNPIndx(ctx, f, pack.file(), fn)
+	return
+}
+
+// PackageZip загружает zip-файл пакета изменений.
+func (c Client) PackageZip(pack Package, filename string, perm os.FileMode) (err error) {
+	return c.PackageZipContext(context.Background(), pack, filename, perm)
+}
+
+// PackageZipContext то же, что и PackageZip, но с контекстом.
+//
+// Если загрузка прервалась, то при повторном вызове файл докачивается (см. DownloadTo).
+func (c Client) PackageZipContext(ctx context.Context, pack Package, filename string, perm os.FileMode) (err error) {
+	_, err = c.downloadTo(ctx, pack.Url, filename, perm, pack.SHA256)
 	return
 }
 
 // PackageDbf загружает dbf-файл пакета изменений.
 func (c Client) PackageDbf(pack Package, filename string, perm os.FileMode) (err error) {
-	var b []byte
-	if b, err = c.downloadZip(pack.Url); err != nil {
-		return
-	}
+	return c.PackageDbfContext(context.Background(), pack, filename, perm)
+}
 
-	if b, err = c.unzipDbf(b); err != nil {
+// PackageDbfContext то же, что и PackageDbf, но с контекстом.
+func (c Client) PackageDbfContext(ctx context.Context, pack Package, filename string, perm os.FileMode) (err error) {
+	var f *os.File
+	if f, _, err = c.downloadPackage(ctx, pack); err != nil {
 		return
 	}
+	defer removeTempFile(f)
 
-	err = os.WriteFile(filename, b, perm)
+	err = c.extractDbf(f, filename, perm)
 	return
 }
 
-func (c *Client) loadPage() (b []byte, err error) {
-	var (
-		resp *http.Response
-	)
+func (c *Client) loadPage(ctx context.Context) (b []byte, err error) {
+	err = c.fetch(ctx, c.baseURL+listUpdatesPath, isHTMLContentType, nil, func(resp *http.Response) (err error) {
+		b, err = getBody(resp)
+		return
+	})
+	return
+}
 
-	if resp, err = c.httpClient.Get(listUpdatesURL); err != nil {
+// newRequest Создает GET-запрос с заголовками клиента.
+func (c Client) newRequest(ctx context.Context, u string) (req *http.Request, err error) {
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, u, nil); err != nil {
 		return
 	}
-	b, err = getBody(resp)
+
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	return
 }
 
-// downloadZip Загружает zip-файл из web-справочника.
-func (c Client) downloadZip(u string) (b []byte, err error) {
+// fetch Выполняет GET-запрос с повторами согласно политике клиента и передает успешный ответ в read.
+// read должна закрыть тело ответа. Ошибки чтения тела также приводят к повтору запроса.
+//
+// prepare, если указана, вызывается перед каждой попыткой и может дополнить запрос.
+func (c Client) fetch(ctx context.Context, u string, validContentType func(string) bool,
+	prepare func(*http.Request) error, read func(*http.Response) error) (err error) {
+	for attempt := 1; ; attempt++ {
+		var (
+			req        *http.Request
+			retryAfter time.Duration
+		)
+
+		if req, err = c.newRequest(ctx, u); err != nil {
+			return
+		}
+
+		if prepare != nil {
+			if err = prepare(req); err != nil {
+				return
+			}
+		}
+
+		if retryAfter, err = c.do(req, validContentType, read); err == nil {
+			return
+		}
+
+		if attempt >= c.retry.MaxAttempts || ctx.Err() != nil || !c.retry.retryable(err) {
+			return
+		}
+
+		wait := c.retry.wait(attempt, retryAfter)
+
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(attempt, wait, err)
+		}
+
+		if err = sleep(ctx, wait); err != nil {
+			return
+		}
+	}
+}
+
+// do Выполняет одну попытку запроса. Если задан кэш, то запрос выполняется условно,
+// а полученный ответ сохраняется в кэш.
+func (c Client) do(req *http.Request, validContentType func(string) bool, readBody func(*http.Response) error) (retryAfter time.Duration, err error) {
+	read := func(resp *http.Response) error {
+		if c.onProgress != nil {
+			resp.Body = &progressReader{
+				ReadCloser: resp.Body,
+				stage:      ProgressDownload,
+				total:      resp.ContentLength,
+				fn:         c.onProgress,
+			}
+		}
+		return readBody(resp)
+	}
+
+	if c.cache != nil {
+		c.cache.prepare(req)
+	}
+
+	httpClient := c.httpClient
+	if req.URL.Scheme == "file" && c.localFiles() {
+		httpClient = fileClient
+	}
+
 	var resp *http.Response
-	if resp, err = c.httpClient.Get(u); err != nil {
+	if resp, err = httpClient.Do(req); err != nil {
+		return
+	}
+	retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	if c.cache != nil && resp.StatusCode == http.StatusNotModified {
+		if resp, err = c.cache.response(resp); err != nil {
+			return
+		}
+		err = read(resp)
+		return
+	}
+
+	if err = checkResponse(resp, validContentType); err != nil {
+		return
+	}
+
+	if c.cache == nil || resp.StatusCode != http.StatusOK {
+		err = read(resp)
+		return
+	}
+
+	// Запись в кэш не обязательна: ее ошибки не должны приводить к ошибке запроса.
+	w, werr := c.cache.writer(resp)
+	if werr != nil || w == nil {
+		err = read(resp)
 		return
 	}
 
-	b, err = getBody(resp)
+	resp.Body = w
+	if err = read(resp); err != nil {
+		w.abort()
+		return
+	}
+	_ = w.commit()
 	return
 }
 
-// unzipPIndex распаковывает индексы из zip-файла.
-func (c Client) unzipPIndex(file []byte) (indexes []PIndx, err error) {
-	if file, err = c.unzipDbf(file); err != nil {
+// downloadZip Загружает zip-файл из web-справочника во временный файл.
+// Временный файл необходимо удалить с помощью removeTempFile.
+func (c Client) downloadZip(ctx context.Context, u string) (f *os.File, err error) {
+	err = c.fetch(ctx, u, isArchiveContentType, nil, func(resp *http.Response) (err error) {
+		if f != nil {
+			removeTempFile(f)
+		}
+		f, err = saveTempFile(resp)
 		return
+	})
+
+	if err != nil && f != nil {
+		removeTempFile(f)
+		f = nil
 	}
+	return
+}
 
-	var table *godbf.DbfTable
-	if table, err = godbf.NewFromByteArray(file, fileEncoding); err != nil {
+// downloadPackage Загружает во временный файл архив пакета изменений и проверяет его контрольную сумму.
+func (c Client) downloadPackage(ctx context.Context, pack Package) (f *os.File, sum string, err error) {
+	if f, err = c.downloadZip(ctx, pack.Url); err != nil {
 		return
 	}
-	indexes, err = dbfToPIndx(table)
+
+	if sum, err = verifyChecksum(pack.Url, f.Name(), pack.SHA256); err != nil {
+		removeTempFile(f)
+		f = nil
+	}
+	return
+}
+
+// unzipPIndex распаковывает индексы из zip-файла.
+func (c Client) unzipPIndex(ctx context.Context, f *os.File, file ReferenceFile) (indexes []PIndx, err error) {
+	indexes = []PIndx{}
+	err = c.eachPIndx(ctx, f, file, func(p PIndx) error {
+		indexes = append(indexes, p)
+		return nil
+	})
+	if err != nil {
+		indexes = nil
+	}
 	return
 }
 
 // unzipNPIndx распаковывает индексы из zip-файла.
-func (c Client) unzipNPIndx(file []byte) (indexes []NPIndx, lastMod time.Time, err error) {
-	file, err = c.unzipDbf(file)
+func (c Client) unzipNPIndx(ctx context.Context, f *os.File, file ReferenceFile) (indexes []NPIndx, lastMod time.Time, err error) {
+	indexes = []NPIndx{}
+	lastMod, err = c.eachNPIndx(ctx, f, file, func(p NPIndx) error {
+		indexes = append(indexes, p)
+		return nil
+	})
 	if err != nil {
+		indexes = nil
+	}
+	return
+}
+
+// eachPIndx последовательно передает в fn индексы из zip-файла.
+// Количество записей сверяется с file.Records, если оно указано.
+func (c Client) eachPIndx(ctx context.Context, f *os.File, file ReferenceFile, fn func(PIndx) error) (err error) {
+	var rc io.ReadCloser
+	if rc, err = c.openDbf(f); err != nil {
 		return
 	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
 
-	var table *godbf.DbfTable
-	if table, err = godbf.NewFromByteArray(file, fileEncoding); err != nil {
+	var dbf *dbfReader
+	if dbf, err = newDbfReader(rc, fileEncoding); err != nil {
 		return
 	}
 
-	if indexes, err = dbfToNPIndx(table); err != nil {
+	if err = verifyRecords(file, dbf.NumberOfRecords()); err != nil {
 		return
 	}
 
-	for _, i := range indexes {
-		if i.UpdatedAt.After(lastMod) {
-			lastMod = i.UpdatedAt
-		}
+	var n int
+	rows := c.rowsProgress(dbf)
+	if err = dbfEachPIndx(ctx, dbf, func(p PIndx) error {
+		n++
+		rows()
+		return fn(p)
+	}); err != nil {
+		return
 	}
+
+	err = verifyRecords(file, n)
 	return
 }
 
-// unzipDbf dbf-файл из zip-файла, который содержит dbf-файл с именем `PIndx[N].dbf`, где N - целое число.
-func (c Client) unzipDbf(b []byte) (unzipBytes []byte, err error) {
-	var zipReader *zip.Reader
-	if zipReader, err = zip.NewReader(bytes.NewReader(b), int64(len(b))); err != nil {
-		return nil, err
+// eachNPIndx последовательно передает в fn индексы из zip-файла пакета изменений.
+// Количество записей сверяется с file.Records, если оно указано.
+func (c Client) eachNPIndx(ctx context.Context, f *os.File, file ReferenceFile, fn func(NPIndx) error) (lastMod time.Time, err error) {
+	var rc io.ReadCloser
+	if rc, err = c.openDbf(f); err != nil {
+		return
+	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	var dbf *dbfReader
+	if dbf, err = newDbfReader(rc, fileEncoding); err != nil {
+		return
+	}
+
+	if err = verifyRecords(file, dbf.NumberOfRecords()); err != nil {
+		return
 	}
 
-	re := regexp.MustCompile(`(?si)^(PIndx|NPIndx)\d*\.dbf$`)
-	for _, zipFile := range zipReader.File {
-		if !re.MatchString(zipFile.Name) {
-			continue
+	var n int
+	rows := c.rowsProgress(dbf)
+	if err = dbfEachNPIndx(ctx, dbf, func(p NPIndx) error {
+		n++
+		rows()
+		if p.UpdatedAt.After(lastMod) {
+			lastMod = p.UpdatedAt
 		}
+		return fn(p)
+	}); err != nil {
+		return
+	}
 
-		if unzipBytes, err = readZipFile(zipFile); err != nil {
-			return
+	err = verifyRecords(file, n)
+	return
+}
+
+// extractDbf распаковывает dbf-файл из zip-файла в файл filename.
+func (c Client) extractDbf(f *os.File, filename string, perm os.FileMode) (err error) {
+	var rc io.ReadCloser
+	if rc, err = c.openDbf(f); err != nil {
+		return
+	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
 		}
-		break
+	}()
+
+	err = writeFile(filename, rc, perm)
+	return
+}
+
+// openDbf открывает для чтения dbf-файл из zip-файла (см. findDbf).
+func (c Client) openDbf(f *os.File) (rc io.ReadCloser, err error) {
+	var fi os.FileInfo
+	if fi, err = f.Stat(); err != nil {
+		return
 	}
 
+	var zipFile *zip.File
+	if zipFile, err = findDbf(f, fi.Size()); err != nil {
+		return
+	}
+
+	if rc, err = zipFile.Open(); err != nil || c.onProgress == nil {
+		return
+	}
+
+	rc = &progressReader{
+		ReadCloser: rc,
+		stage:      ProgressInflate,
+		total:      int64(zipFile.UncompressedSize64),
+		fn:         c.onProgress,
+	}
 	return
 }
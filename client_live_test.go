@@ -0,0 +1,153 @@
+//go:build live
+
+package pindxru
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Indexes(t *testing.T) {
+	u, d, err := cTest.Indexes(testReferenceRows, nil)
+	require.Nil(t, err)
+	require.IsType(t, d, time.Time{})
+	require.False(t, d.IsZero())
+	require.True(t, len(u) > 10000)
+
+	// (!) обновлений нет
+	lastMod := time.Now().Add(time.Hour * 1000)
+	u, d, err = cTest.Indexes(testReferenceRows, &lastMod)
+	require.Nil(t, err)
+	require.IsType(t, d, time.Time{})
+	require.True(t, d.IsZero())
+	require.Len(t, u, 0)
+
+	lastMod = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
+	u, d, err = cTest.Indexes(testReferenceRows, &lastMod)
+	require.Nil(t, err)
+	require.IsType(t, d, time.Time{})
+	require.False(t, d.IsZero())
+	require.True(t, len(u) > 10000)
+}
+
+func Test_IndexesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	u, _, err := cTest.IndexesContext(ctx, testReferenceRows, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, u, 0)
+}
+
+func Test_EachIndex(t *testing.T) {
+	errStop := errors.New("stop")
+	n := 0
+	lastMod, err := cTest.EachIndex(context.Background(), testReferenceRows, func(p PIndx) error {
+		require.NotEmpty(t, p.Index)
+		if n++; n == 10 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 10, n)
+	require.False(t, lastMod.IsZero())
+}
+
+func Test_IndexesZip(t *testing.T) {
+	filename := filepath.Join(testdata, "indexes-"+testZipFile)
+	lastMod, ok, err := cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, nil)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.False(t, lastMod.IsZero())
+
+	testCheckFile(t, filename)
+
+	filename = filepath.Join(testdata, "indexesWithDate-"+testZipFile)
+	// (!) обновлений нет
+	d := time.Now().Add(time.Hour * 1000)
+	lastMod, ok, err = cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, &d)
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.True(t, lastMod.IsZero())
+
+	d = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
+	lastMod, ok, err = cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, &d)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.False(t, lastMod.IsZero())
+
+	testCheckFile(t, filename)
+}
+
+func Test_IndexesDbf(t *testing.T) {
+	filename := filepath.Join(testdata, "indexes-"+testDbfFile)
+	lastMod, ok, err := cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, nil)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.False(t, lastMod.IsZero())
+
+	testCheckFile(t, filename)
+
+	filename = filepath.Join(testdata, "indexesWithDate-"+testDbfFile)
+	// (!) обновлений нет
+	d := time.Now().Add(time.Hour * 1000)
+	lastMod, ok, err = cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, &d)
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.True(t, lastMod.IsZero())
+
+	d = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
+	lastMod, ok, err = cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, &d)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.False(t, lastMod.IsZero())
+
+	testCheckFile(t, filename)
+}
+
+func Test_Packages(t *testing.T) {
+	// (!) обновлений нет
+	d := time.Now().Add(time.Hour * 1000)
+	pack, err := testReferenceRows.GetUpdatePackages(&d)
+	require.Nil(t, err)
+	require.Len(t, pack, 0)
+
+	lastMod, err := cTest.GetPackageIndexes(&testPackages[0])
+	require.Nil(t, err)
+	require.False(t, lastMod.IsZero())
+	require.True(t, len(testPackages[0].Indexes) > 0)
+}
+
+func Test_EachPackageIndex(t *testing.T) {
+	n := 0
+	lastMod, err := cTest.EachPackageIndex(context.Background(), testPackages[0], func(p NPIndx) error {
+		n++
+		return nil
+	})
+	require.Nil(t, err)
+	require.False(t, lastMod.IsZero())
+	require.Equal(t, testPackages[0].NumberRecords, n)
+}
+
+func Test_PackageZip(t *testing.T) {
+	filename := filepath.Join(testdata, "package-"+testZipFile)
+	err := cTest.PackageZip(testPackages[0], filename, os.ModePerm)
+	require.Nil(t, err)
+
+	testCheckFile(t, filename)
+}
+
+func Test_PackageDbf(t *testing.T) {
+	filename := filepath.Join(testdata, "package-"+testDbfFile)
+	err := cTest.PackageDbf(testPackages[0], filename, os.ModePerm)
+	require.Nil(t, err)
+
+	testCheckFile(t, filename)
+}
@@ -1,124 +1,196 @@
 package pindxru
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
 	"github.com/stretchr/testify/require"
 )
 
-func Test_Indexes(t *testing.T) {
-	u, d, err := cTest.Indexes(testReferenceRows, nil)
+func TestClient_GetReferenceRows(t *testing.T) {
+	for _, page := range []string{"ops_2019.html", "ops_2021.html"} {
+		ts := newTestServer(t, page)
+		rows, err := NewClient(nil, WithBaseURL(ts.URL)).GetReferenceRows()
+		require.Nil(t, err, page)
+		require.True(t, len(rows) > 1, page)
+
+		for _, r := range rows {
+			require.False(t, r.Date.IsZero(), page)
+			require.NotEmpty(t, r.Number, page)
+			require.Regexp(t, `^`+ts.URL+`/documents/.+/NPIndx\d+\.zip/`, r.Update.Url, page)
+			require.Regexp(t, `^`+ts.URL+`/documents/.+/PIndx\d+\.zip/`, r.Full.Url, page)
+			require.Equal(t, len(testNPIndxRows), r.Update.Records, page)
+			require.Equal(t, len(testPIndxRows), r.Full.Records, page)
+		}
+	}
+
+	rows, err := NewClient(nil, WithBaseURL(newTestServer(t, "ops_2021.html").URL)).GetReferenceRows()
 	require.Nil(t, err)
-	require.IsType(t, d, time.Time{})
-	require.False(t, d.IsZero())
-	require.True(t, len(u) > 10000)
+	require.Equal(t, "05", rows[1].Number)
+	require.Equal(t, time.Date(2021, 3, 19, 0, 0, 0, 0, time.UTC), rows[1].Date)
 
-	// (!) обновлений нет
-	lastMod := time.Now().Add(time.Hour * 1000)
-	u, d, err = cTest.Indexes(testReferenceRows, &lastMod)
-	require.Nil(t, err)
-	require.IsType(t, d, time.Time{})
-	require.True(t, d.IsZero())
-	require.Len(t, u, 0)
-
-	lastMod = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
-	u, d, err = cTest.Indexes(testReferenceRows, &lastMod)
-	require.Nil(t, err)
-	require.IsType(t, d, time.Time{})
-	require.False(t, d.IsZero())
-	require.True(t, len(u) > 10000)
+	// страница без таблицы обновлений
+	ts := newTestServer(t, "page.htm")
+	_, err = NewClient(nil, WithBaseURL(ts.URL)).GetReferenceRows()
+	require.ErrorIs(t, err, ErrPageLayoutChanged)
 }
 
-func Test_IndexesZip(t *testing.T) {
-	filename := filepath.Join(testdata, "indexes-"+testZipFile)
-	lastMod, ok, err := cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, nil)
-	require.Nil(t, err)
-	require.True(t, ok)
-	require.False(t, lastMod.IsZero())
+func TestClient_Indexes(t *testing.T) {
+	c, rows := newTestClient(t)
 
-	testCheckFile(t, filename)
+	indexes, lastMod, err := c.Indexes(rows, nil)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 2, 16, 0, 0, 0, 0, time.UTC), lastMod)
+	require.Len(t, indexes, len(testPIndxRows))
+	require.Equal(t, PIndx{
+		Index:      "664000",
+		OpsName:    "ИРКУТСК ПОЧТАМТ",
+		OpsType:    "ПОЧТАМТ",
+		OpsSub:     "664700",
+		Region:     "ИРКУТСКАЯ ОБЛАСТЬ",
+		City:       "ИРКУТСК",
+		UpdatedAt:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		OldIndex:   "664000",
+		RegionCode: 38,
+	}, indexes[1])
+	require.Equal(t, 72, indexes[4].RegionCode)
 
-	filename = filepath.Join(testdata, "indexesWithDate-"+testZipFile)
 	// (!) обновлений нет
-	d := time.Now().Add(time.Hour * 1000)
-	lastMod, ok, err = cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, &d)
+	d := lastMod
+	indexes, lastMod, err = c.Indexes(rows, &d)
 	require.Nil(t, err)
-	require.False(t, ok)
 	require.True(t, lastMod.IsZero())
+	require.Len(t, indexes, 0)
 
-	d = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
-	lastMod, ok, err = cTest.IndexesZip(testReferenceRows, filename, os.ModePerm, &d)
+	d = time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	indexes, lastMod, err = c.Indexes(rows, &d)
 	require.Nil(t, err)
-	require.True(t, ok)
 	require.False(t, lastMod.IsZero())
+	require.Len(t, indexes, len(testPIndxRows))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.IndexesContext(ctx, rows, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClient_EachIndex(t *testing.T) {
+	c, rows := newTestClient(t)
 
-	testCheckFile(t, filename)
+	var indexes []string
+	_, err := c.EachIndex(context.Background(), rows, func(p PIndx) error {
+		indexes = append(indexes, p.Index)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"664700", "664000", "664001", "664520", "628001"}, indexes)
+
+	errStop := errors.New("stop")
+	n := 0
+	_, err = c.EachIndex(context.Background(), rows, func(p PIndx) error {
+		if n++; n == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 2, n)
 }
 
-func Test_IndexesDbf(t *testing.T) {
-	filename := filepath.Join(testdata, "indexes-"+testDbfFile)
-	lastMod, ok, err := cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, nil)
+func TestClient_IndexesZip(t *testing.T) {
+	c, rows := newTestClient(t)
+	filename := filepath.Join(t.TempDir(), testZipFile)
+
+	lastMod, ok, err := c.IndexesZip(rows, filename, os.ModePerm, nil)
 	require.Nil(t, err)
 	require.True(t, ok)
 	require.False(t, lastMod.IsZero())
 
-	testCheckFile(t, filename)
+	b, err := os.ReadFile(filepath.Join(testdata, testPIndxZip))
+	require.Nil(t, err)
+	testRequireFile(t, filename, b)
 
-	filename = filepath.Join(testdata, "indexesWithDate-"+testDbfFile)
 	// (!) обновлений нет
-	d := time.Now().Add(time.Hour * 1000)
-	lastMod, ok, err = cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, &d)
+	d := lastMod
+	lastMod, ok, err = c.IndexesZip(rows, filename, os.ModePerm, &d)
 	require.Nil(t, err)
 	require.False(t, ok)
 	require.True(t, lastMod.IsZero())
+}
 
-	d = time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
-	lastMod, ok, err = cTest.IndexesDbf(testReferenceRows, filename, os.ModePerm, &d)
+func TestClient_IndexesDbf(t *testing.T) {
+	c, rows := newTestClient(t)
+	filename := filepath.Join(t.TempDir(), testDbfFile)
+
+	lastMod, ok, err := c.IndexesDbf(rows, filename, os.ModePerm, nil)
 	require.Nil(t, err)
 	require.True(t, ok)
 	require.False(t, lastMod.IsZero())
+	testRequireFile(t, filename, dbftest.MakeDbf(dbftest.PIndxFields, testPIndxRows))
 
-	testCheckFile(t, filename)
+	// (!) обновлений нет
+	d := lastMod
+	lastMod, ok, err = c.IndexesDbf(rows, filename, os.ModePerm, &d)
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.True(t, lastMod.IsZero())
 }
 
-func Test_Packages(t *testing.T) {
-	// (!) обновлений нет
-	d := time.Now().Add(time.Hour * 1000)
-	pack, err := testReferenceRows.GetUpdatePackages(&d)
+func TestClient_GetPackageIndexes(t *testing.T) {
+	c, rows := newTestClient(t)
+
+	packages, err := rows.GetUpdatePackages(nil)
 	require.Nil(t, err)
-	require.Len(t, pack, 0)
 
-	lastMod, err := cTest.GetPackageIndexes(&testPackages[0])
+	lastMod, err := c.GetPackageIndexes(&packages[0])
 	require.Nil(t, err)
-	require.False(t, lastMod.IsZero())
-	require.True(t, len(testPackages[0].Indexes) > 0)
+	require.Equal(t, time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC), lastMod)
+	require.Len(t, packages[0].Indexes, len(testNPIndxRows))
+	require.Equal(t, "664520", packages[0].Indexes[1].Index)
+	require.Equal(t, "664521", packages[0].Indexes[1].NewIndex)
+	require.Equal(t, 38, packages[0].Indexes[1].RegionCode)
 }
 
-func Test_PackageZip(t *testing.T) {
-	filename := filepath.Join(testdata, "package-"+testZipFile)
-	err := cTest.PackageZip(testPackages[0], filename, os.ModePerm)
+func TestClient_EachPackageIndex(t *testing.T) {
+	c, rows := newTestClient(t)
+
+	packages, err := rows.GetUpdatePackages(nil)
 	require.Nil(t, err)
 
-	testCheckFile(t, filename)
+	n := 0
+	lastMod, err := c.EachPackageIndex(context.Background(), packages[0], func(p NPIndx) error {
+		n++
+		return nil
+	})
+	require.Nil(t, err)
+	require.False(t, lastMod.IsZero())
+	require.Equal(t, packages[0].NumberRecords, n)
 }
 
-func Test_PackageDbf(t *testing.T) {
-	filename := filepath.Join(testdata, "package-"+testDbfFile)
-	err := cTest.PackageDbf(testPackages[0], filename, os.ModePerm)
+func TestClient_PackageZip(t *testing.T) {
+	c, rows := newTestClient(t)
+	filename := filepath.Join(t.TempDir(), testZipFile)
+
+	packages, err := rows.GetUpdatePackages(nil)
 	require.Nil(t, err)
+	require.Nil(t, c.PackageZip(packages[0], filename, os.ModePerm))
 
-	testCheckFile(t, filename)
+	b, err := os.ReadFile(filepath.Join(testdata, testNPIndxZip))
+	require.Nil(t, err)
+	testRequireFile(t, filename, b)
 }
 
-func testCheckFile(t *testing.T, filename string) {
-	f, err := os.Open(filename)
-	require.Nil(t, err)
-	fi, err := f.Stat()
-	require.Nil(t, err)
-	require.True(t, fi.Size() > 0)
+func TestClient_PackageDbf(t *testing.T) {
+	c, rows := newTestClient(t)
+	filename := filepath.Join(t.TempDir(), testDbfFile)
 
-	require.Nil(t, os.Remove(filename))
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+	require.Nil(t, c.PackageDbf(packages[0], filename, os.ModePerm))
+	testRequireFile(t, filename, dbftest.MakeDbf(dbftest.NPIndxFields, testNPIndxRows))
 }
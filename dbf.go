@@ -0,0 +1,114 @@
+package pindxru
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+const (
+	dbfHeaderSize     = 32
+	dbfFieldSize      = 32
+	dbfFieldTerminate = 0x0D
+)
+
+// dbfReader Потоковое чтение записей dbf-файла без загрузки файла в память.
+type dbfReader struct {
+	r          *bufio.Reader
+	decoder    *encoding.Decoder
+	numRecords int
+	fieldLens  []int
+	record     []byte
+	read       int
+}
+
+// newDbfReader Читает заголовок dbf-файла.
+func newDbfReader(r io.Reader, enc encoding.Encoding) (d *dbfReader, err error) {
+	d = &dbfReader{
+		r:       bufio.NewReader(r),
+		decoder: enc.NewDecoder(),
+	}
+
+	header := make([]byte, dbfHeaderSize)
+	if _, err = io.ReadFull(d.r, header); err != nil {
+		return nil, err
+	}
+
+	d.numRecords = int(binary.LittleEndian.Uint32(header[4:8]))
+	headerLen := int(binary.LittleEndian.Uint16(header[8:10]))
+	recordLen := int(binary.LittleEndian.Uint16(header[10:12]))
+
+	read := dbfHeaderSize
+	field := make([]byte, dbfFieldSize)
+	for {
+		var b []byte
+		if b, err = d.r.Peek(1); err != nil {
+			return nil, err
+		}
+
+		if b[0] == dbfFieldTerminate {
+			break
+		}
+
+		if _, err = io.ReadFull(d.r, field); err != nil {
+			return nil, err
+		}
+		read += dbfFieldSize
+		d.fieldLens = append(d.fieldLens, int(field[16]))
+	}
+
+	// терминатор заголовка и возможные служебные байты до первой записи
+	if headerLen > read {
+		if _, err = d.r.Discard(headerLen - read); err != nil {
+			return nil, err
+		}
+	}
+
+	total := 1
+	for _, l := range d.fieldLens {
+		total += l
+	}
+
+	if total != recordLen {
+		return nil, fmt.Errorf("%w: длина записи %d не совпадает с суммой длин полей %d", ErrInvalidDbf, recordLen, total)
+	}
+
+	d.record = make([]byte, recordLen)
+	return
+}
+
+// NumberOfRecords Количество записей, указанное в заголовке.
+func (d *dbfReader) NumberOfRecords() int {
+	return d.numRecords
+}
+
+// Next Возвращает следующую запись. В конце файла возвращает io.EOF.
+func (d *dbfReader) Next() (row []string, err error) {
+	if d.read >= d.numRecords {
+		return nil, io.EOF
+	}
+
+	if _, err = io.ReadFull(d.r, d.record); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	d.read++
+
+	row = make([]string, len(d.fieldLens))
+	pos := 1 // первый байт - признак удаления записи
+	for i, l := range d.fieldLens {
+		var b []byte
+		if b, err = d.decoder.Bytes(d.record[pos : pos+l]); err != nil {
+			return nil, err
+		}
+		row[i] = strings.TrimSpace(string(b))
+		pos += l
+	}
+	return
+}
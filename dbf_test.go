@@ -0,0 +1,40 @@
+package pindxru
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dbfReader(t *testing.T) {
+	b := dbftest.MakeDbf(dbftest.CharFields(6, 20), [][]string{
+		{"101000", "Москва 101"},
+		{"664000", "Иркутск"},
+	})
+
+	dbf, err := newDbfReader(bytes.NewReader(b), fileEncoding)
+	require.Nil(t, err)
+	require.Equal(t, 2, dbf.NumberOfRecords())
+
+	row, err := dbf.Next()
+	require.Nil(t, err)
+	require.Equal(t, []string{"101000", "Москва 101"}, row)
+
+	row, err = dbf.Next()
+	require.Nil(t, err)
+	require.Equal(t, []string{"664000", "Иркутск"}, row)
+
+	_, err = dbf.Next()
+	require.Equal(t, io.EOF, err)
+
+	// обрезанный файл
+	dbf, err = newDbfReader(bytes.NewReader(b[:len(b)-10]), fileEncoding)
+	require.Nil(t, err)
+	_, err = dbf.Next()
+	require.Nil(t, err)
+	_, err = dbf.Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,203 @@
+package pindxru
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Directory Справочник почтовых индексов в памяти с поиском по индексу, региону, населенному пункту,
+// району и старому индексу.
+//
+// Методы безопасны для одновременного вызова из нескольких горутин. Replace заменяет
+// справочник целиком: каждый вызов поиска видит либо старый, либо новый снимок.
+type Directory struct {
+	snapshot atomic.Value
+}
+
+// directorySnapshot Неизменяемый снимок справочника.
+type directorySnapshot struct {
+	// Записи, упорядоченные по индексу
+	items    []PIndx
+	byIndex  map[string]int
+	byRegion map[int][]int
+	byCity   map[directoryKey][]int
+	byArea   map[directoryKey][]int
+	byOld    map[string][]int
+	// Подчиненные объекты по индексу вышестоящего
+	children map[string][]int
+
+	// Индекс для Search строится при первом поиске
+	searchOnce sync.Once
+	search     *searchIndex
+}
+
+// directoryKey Ключ поиска по региону и населенному пункту или району.
+type directoryKey struct {
+	region string
+	name   string
+}
+
+// NewDirectory Создает справочник из записей indexes.
+func NewDirectory(indexes []PIndx) (d *Directory) {
+	d = &Directory{}
+	d.Replace(indexes)
+	return
+}
+
+// Replace Заменяет записи справочника. Если индекс повторяется, то используется последняя запись.
+func (d *Directory) Replace(indexes []PIndx) {
+	d.snapshot.Store(newDirectorySnapshot(indexes))
+}
+
+func newDirectorySnapshot(indexes []PIndx) (s *directorySnapshot) {
+	last := make(map[string]int, len(indexes))
+	for i, p := range indexes {
+		last[p.Index] = i
+	}
+
+	s = &directorySnapshot{
+		items:    make([]PIndx, 0, len(last)),
+		byIndex:  make(map[string]int, len(last)),
+		byRegion: map[int][]int{},
+		byCity:   map[directoryKey][]int{},
+		byArea:   map[directoryKey][]int{},
+		byOld:    map[string][]int{},
+		children: map[string][]int{},
+	}
+
+	for i, p := range indexes {
+		if last[p.Index] == i {
+			s.items = append(s.items, p)
+		}
+	}
+
+	sort.Slice(s.items, func(i, j int) bool {
+		return s.items[i].Index < s.items[j].Index
+	})
+
+	for i, p := range s.items {
+		s.byIndex[p.Index] = i
+
+		if p.RegionCode > 0 {
+			s.byRegion[p.RegionCode] = append(s.byRegion[p.RegionCode], i)
+		}
+
+		if p.OldIndex != "" {
+			s.byOld[p.OldIndex] = append(s.byOld[p.OldIndex], i)
+		}
+
+		if !p.isRoot() {
+			s.children[p.OpsSub] = append(s.children[p.OpsSub], i)
+		}
+
+		// объект автономного округа ищется и по области, и по округу
+		for _, region := range []string{p.Region, p.Autonomy} {
+			if region == "" {
+				continue
+			}
+
+			if p.City != "" {
+				key := newDirectoryKey(region, p.City)
+				s.byCity[key] = append(s.byCity[key], i)
+			}
+
+			if p.Area != "" {
+				key := newDirectoryKey(region, p.Area)
+				s.byArea[key] = append(s.byArea[key], i)
+			}
+		}
+	}
+	return
+}
+
+func newDirectoryKey(region, name string) directoryKey {
+	return directoryKey{
+		region: strings.ToUpper(strings.TrimSpace(region)),
+		name:   strings.ToUpper(strings.TrimSpace(name)),
+	}
+}
+
+// emptyDirectory Снимок справочника, созданного без NewDirectory.
+var emptyDirectory = newDirectorySnapshot(nil)
+
+func (d *Directory) load() *directorySnapshot {
+	if s, ok := d.snapshot.Load().(*directorySnapshot); ok {
+		return s
+	}
+	return emptyDirectory
+}
+
+// Len Количество записей.
+func (d *Directory) Len() int {
+	return len(d.load().items)
+}
+
+// All Возвращает все записи, упорядоченные по индексу.
+func (d *Directory) All() []PIndx {
+	return append([]PIndx{}, d.load().items...)
+}
+
+// Get Возвращает запись по индексу.
+func (d *Directory) Get(index string) (p PIndx, ok bool) {
+	s := d.load()
+
+	var i int
+	if i, ok = s.byIndex[index]; ok {
+		p = s.items[i]
+	}
+	return
+}
+
+// ByRegionCode Возвращает записи региона с кодом code.
+func (d *Directory) ByRegionCode(code int) []PIndx {
+	s := d.load()
+	return s.pick(s.byRegion[code])
+}
+
+// ByCity Возвращает записи населенного пункта city в регионе region. Регион - это название
+// области, края, республики или автономного округа, регистр не учитывается.
+func (d *Directory) ByCity(region, city string) []PIndx {
+	s := d.load()
+	return s.pick(s.byCity[newDirectoryKey(region, city)])
+}
+
+// ByArea Возвращает записи района area в регионе region (см. ByCity).
+func (d *Directory) ByArea(region, area string) []PIndx {
+	s := d.load()
+	return s.pick(s.byArea[newDirectoryKey(region, area)])
+}
+
+// ByOldIndex Возвращает записи с индексом до ввода действующей системы индексации old.
+func (d *Directory) ByOldIndex(old string) []PIndx {
+	s := d.load()
+	return s.pick(s.byOld[old])
+}
+
+// ByPrefix Возвращает записи, индекс которых начинается с prefix, например, все индексы
+// города по первым трем цифрам.
+func (d *Directory) ByPrefix(prefix string) (indexes []PIndx) {
+	s := d.load()
+	from := sort.Search(len(s.items), func(i int) bool {
+		return s.items[i].Index >= prefix
+	})
+
+	indexes = []PIndx{}
+	for _, p := range s.items[from:] {
+		if !strings.HasPrefix(p.Index, prefix) {
+			break
+		}
+		indexes = append(indexes, p)
+	}
+	return
+}
+
+// pick Возвращает копии записей с номерами positions.
+func (s *directorySnapshot) pick(positions []int) (indexes []PIndx) {
+	indexes = make([]PIndx, len(positions))
+	for i, pos := range positions {
+		indexes[i] = s.items[pos]
+	}
+	return
+}
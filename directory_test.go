@@ -0,0 +1,98 @@
+package pindxru
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory(t *testing.T) {
+	snapshot := testReadZip(t, testPIndxZip, ReadPIndxZip)
+	d := NewDirectory(snapshot)
+	require.Equal(t, len(testPIndxRows), d.Len())
+
+	p, ok := d.Get("664001")
+	require.True(t, ok)
+	require.Equal(t, "ИРКУТСК 1", p.OpsName)
+	_, ok = d.Get("000000")
+	require.False(t, ok)
+
+	require.Len(t, d.ByRegionCode(38), 4)
+	require.Len(t, d.ByRegionCode(72), 1)
+	require.Len(t, d.ByRegionCode(1), 0)
+
+	city := d.ByCity("Иркутская область", "иркутск")
+	require.Len(t, city, 3)
+	require.Equal(t, "664000", city[0].Index)
+	require.Len(t, d.ByCity("ХАНТЫ-МАНСИЙСКИЙ-ЮГРА АВТОНОМНЫЙ ОКРУГ", "ХАНТЫ-МАНСИЙСК"), 1)
+	require.Len(t, d.ByCity("ТЮМЕНСКАЯ ОБЛАСТЬ", "ХАНТЫ-МАНСИЙСК"), 1)
+
+	area := d.ByArea("ИРКУТСКАЯ ОБЛАСТЬ", "ИРКУТСКИЙ РАЙОН")
+	require.Len(t, area, 1)
+	require.Equal(t, "664520", area[0].Index)
+
+	old := d.ByOldIndex("664000")
+	require.Len(t, old, 1)
+	require.Equal(t, "ИРКУТСК ПОЧТАМТ", old[0].OpsName)
+
+	indexes := d.ByPrefix("6640")
+	require.Len(t, indexes, 2)
+	require.Equal(t, "664000", indexes[0].Index)
+	require.Equal(t, "664001", indexes[1].Index)
+	require.Len(t, d.ByPrefix("664"), 4)
+	require.Len(t, d.ByPrefix(""), len(testPIndxRows))
+	require.Len(t, d.ByPrefix("9"), 0)
+
+	// результаты - копии записей
+	indexes[0].OpsName = "-"
+	p, _ = d.Get("664000")
+	require.Equal(t, "ИРКУТСК ПОЧТАМТ", p.OpsName)
+
+	// повторяющийся индекс
+	d = NewDirectory(append(append([]PIndx{}, snapshot...), PIndx{Index: "664001", OpsName: "ИРКУТСК 1 ОПС"}))
+	require.Equal(t, len(testPIndxRows), d.Len())
+	p, _ = d.Get("664001")
+	require.Equal(t, "ИРКУТСК 1 ОПС", p.OpsName)
+
+	require.Equal(t, 0, (&Directory{}).Len())
+	_, ok = (&Directory{}).Get("664001")
+	require.False(t, ok)
+}
+
+func TestDirectory_Replace(t *testing.T) {
+	snapshot := testReadZip(t, testPIndxZip, ReadPIndxZip)
+	updated, _, err := Apply(snapshot, Package{Indexes: testReadZip(t, testNPIndxZip, ReadNPIndxZip)})
+	require.Nil(t, err)
+
+	d := NewDirectory(snapshot)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				// снимок заменяется целиком
+				if n := len(d.ByPrefix("664")); n != 4 && n != 5 {
+					t.Errorf("ByPrefix: %d", n)
+				}
+				if n := len(d.ByCity("ИРКУТСКАЯ ОБЛАСТЬ", "ИРКУТСК")); n != 3 && n != 4 {
+					t.Errorf("ByCity: %d", n)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			d.Replace(updated)
+		} else {
+			d.Replace(snapshot)
+		}
+	}
+	wg.Wait()
+
+	d.Replace(updated)
+	_, ok := d.Get("664521")
+	require.True(t, ok)
+}
@@ -0,0 +1,167 @@
+package pindxru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// partSuffix Суффикс файла с незавершенной загрузкой.
+	partSuffix = ".part"
+	// validatorSuffix Суффикс файла с ETag или Last-Modified незавершенной загрузки.
+	validatorSuffix = ".part.validator"
+)
+
+// DownloadTo Загружает файл u в path.
+//
+// Данные сначала записываются в `path.part`. Если загрузка прервалась, то повторный вызов
+// докачивает файл с помощью заголовков Range и If-Range, при условии, что сервер вернул ETag
+// или Last-Modified. Если файл на сервере изменился, то загрузка начинается заново.
+func (c Client) DownloadTo(ctx context.Context, u, path string) (err error) {
+	_, err = c.downloadTo(ctx, u, path, 0666, "")
+	return
+}
+
+// downloadTo Загружает файл u в path и возвращает его контрольную сумму sum. Если указана
+// ожидаемая контрольная сумма expected и она не совпадает, то незавершенный файл удаляется
+// и path не создается.
+func (c Client) downloadTo(ctx context.Context, u, path string, perm os.FileMode, expected string) (sum string, err error) {
+	part := path + partSuffix
+	validatorFile := path + validatorSuffix
+
+	prepare := func(req *http.Request) (err error) {
+		var (
+			fi        os.FileInfo
+			validator []byte
+		)
+
+		if fi, err = os.Stat(part); err != nil || fi.Size() == 0 {
+			if os.IsNotExist(err) {
+				err = nil
+			}
+			return
+		}
+
+		if validator, err = os.ReadFile(validatorFile); err != nil || len(validator) == 0 {
+			// без валидатора нельзя убедиться, что файл на сервере не изменился
+			if os.IsNotExist(err) {
+				err = nil
+			}
+			return
+		}
+
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", fi.Size()))
+		req.Header.Set("If-Range", string(validator))
+		return
+	}
+
+	read := func(resp *http.Response) (err error) {
+		defer func() {
+			if derr := resp.Body.Close(); derr != nil && err == nil {
+				err = derr
+			}
+		}()
+
+		flag := os.O_WRONLY | os.O_CREATE
+		if resp.StatusCode == http.StatusPartialContent {
+			var fi os.FileInfo
+			if fi, err = os.Stat(part); err != nil {
+				return
+			}
+
+			if start, ok := parseContentRangeStart(resp.Header.Get("Content-Range")); !ok || start != fi.Size() {
+				removePart(part, validatorFile)
+				return fmt.Errorf("%w: %s", ErrContentRange, resp.Header.Get("Content-Range"))
+			}
+			flag |= os.O_APPEND
+
+		} else {
+			flag |= os.O_TRUNC
+			if err = writeValidator(validatorFile, resp.Header, perm); err != nil {
+				return
+			}
+		}
+
+		var f *os.File
+		if f, err = os.OpenFile(part, flag, perm); err != nil {
+			return
+		}
+
+		defer func() {
+			if derr := f.Close(); derr != nil && err == nil {
+				err = derr
+			}
+		}()
+
+		_, err = io.Copy(f, resp.Body)
+		return
+	}
+
+	err = c.fetch(ctx, u, isArchiveContentType, prepare, read)
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// незавершенный файл больше файла на сервере
+		removePart(part, validatorFile)
+		err = c.fetch(ctx, u, isArchiveContentType, prepare, read)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if sum, err = verifyChecksum(u, part, expected); err != nil {
+		removePart(part, validatorFile)
+		return
+	}
+
+	if err = os.Rename(part, path); err != nil {
+		return
+	}
+	_ = os.Remove(validatorFile)
+	return
+}
+
+// writeValidator Сохраняет ETag или Last-Modified ответа для последующей докачки.
+// Слабый ETag не подходит для If-Range, поэтому в этом случае используется Last-Modified.
+func writeValidator(filename string, header http.Header, perm os.FileMode) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+
+	if validator == "" {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(filename, []byte(validator), perm)
+}
+
+func removePart(part, validatorFile string) {
+	_ = os.Remove(part)
+	_ = os.Remove(validatorFile)
+}
+
+// parseContentRangeStart Возвращает начало диапазона из заголовка `Content-Range: bytes 100-999/1000`.
+func parseContentRangeStart(value string) (start int64, ok bool) {
+	value = strings.TrimPrefix(value, "bytes ")
+	i := strings.Index(value, "-")
+	if i <= 0 {
+		return
+	}
+
+	var err error
+	if start, err = strconv.ParseInt(value[:i], 10, 64); err != nil {
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,75 @@
+package pindxru
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DownloadTo(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var ranges []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "PIndx01.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil)
+	path := filepath.Join(t.TempDir(), testZipFile)
+
+	// новая загрузка
+	require.Nil(t, c.DownloadTo(context.Background(), ts.URL, path))
+	testRequireFile(t, path, content)
+	require.NoFileExists(t, path+partSuffix)
+	require.NoFileExists(t, path+validatorSuffix)
+	require.Equal(t, []string{""}, ranges)
+
+	// докачка
+	ranges = nil
+	require.Nil(t, os.WriteFile(path+partSuffix, content[:4000], 0666))
+	require.Nil(t, os.WriteFile(path+validatorSuffix, []byte(`"v1"`), 0666))
+	require.Nil(t, c.DownloadTo(context.Background(), ts.URL, path))
+	testRequireFile(t, path, content)
+	require.Equal(t, []string{"bytes=4000-"}, ranges)
+
+	// файл на сервере изменился
+	ranges = nil
+	require.Nil(t, os.WriteFile(path+partSuffix, []byte("garbage"), 0666))
+	require.Nil(t, os.WriteFile(path+validatorSuffix, []byte(`"v0"`), 0666))
+	require.Nil(t, c.DownloadTo(context.Background(), ts.URL, path))
+	testRequireFile(t, path, content)
+	require.Equal(t, []string{"bytes=7-"}, ranges)
+
+	// незавершенный файл больше файла на сервере
+	ranges = nil
+	require.Nil(t, os.WriteFile(path+partSuffix, append(content, content...), 0666))
+	require.Nil(t, os.WriteFile(path+validatorSuffix, []byte(`"v1"`), 0666))
+	require.Nil(t, c.DownloadTo(context.Background(), ts.URL, path))
+	testRequireFile(t, path, content)
+	require.Equal(t, []string{"bytes=20000-", ""}, ranges)
+}
+
+func Test_parseContentRangeStart(t *testing.T) {
+	start, ok := parseContentRangeStart("bytes 100-999/1000")
+	require.True(t, ok)
+	require.Equal(t, int64(100), start)
+
+	_, ok = parseContentRangeStart("bytes */1000")
+	require.False(t, ok)
+}
+
+func testRequireFile(t *testing.T, filename string, content []byte) {
+	b, err := os.ReadFile(filename)
+	require.Nil(t, err)
+	require.Equal(t, content, b)
+}
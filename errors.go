@@ -0,0 +1,131 @@
+package pindxru
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrPageLayoutChanged на странице web-справочника не найдена таблица с обновлениями.
+	ErrPageLayoutChanged = errors.New("pindxru: изменилась разметка страницы справочника")
+	// ErrNoDbfInArchive в zip-архиве нет dbf-файла `PIndx[N].dbf` или `NPIndx[N].dbf`.
+	ErrNoDbfInArchive = errors.New("pindxru: в архиве не найден dbf-файл")
+	// ErrInvalidDbf некорректный заголовок dbf-файла.
+	ErrInvalidDbf = errors.New("pindxru: некорректный dbf-файл")
+	// ErrFieldCount количество полей в записи dbf-файла не соответствует структуре.
+	ErrFieldCount = errors.New("pindxru: неверное количество полей")
+	// ErrInvalidIndex почтовый индекс имеет неверный формат.
+	ErrInvalidIndex = errors.New("pindxru: неверный формат почтового индекса")
+	// ErrRegionNotFound регион не найден.
+	ErrRegionNotFound = errors.New("pindxru: регион не найден")
+	// ErrContentType сервер вернул содержимое неожиданного типа, например, html-страницу вместо архива.
+	ErrContentType = errors.New("pindxru: неожиданный тип содержимого")
+	// ErrContentRange сервер вернул диапазон, не совпадающий с уже загруженной частью файла.
+	ErrContentRange = errors.New("pindxru: неожиданный диапазон при докачке")
+	// ErrPackageNotLoaded у пакета изменений не загружены индексы.
+	ErrPackageNotLoaded = errors.New("pindxru: индексы пакета изменений не загружены")
+	// ErrIndexNotFound почтовый индекс не найден.
+	ErrIndexNotFound = errors.New("pindxru: почтовый индекс не найден")
+	// ErrIndexClosed объект почтовой связи с индексом закрыт.
+	ErrIndexClosed = errors.New("pindxru: объект почтовой связи закрыт")
+	// ErrAmbiguousIndex старому индексу соответствует несколько действующих.
+	ErrAmbiguousIndex = errors.New("pindxru: индексу соответствует несколько действующих")
+	// ErrInvalidReferenceRow некорректное значение в строке списка обновлений.
+	ErrInvalidReferenceRow = errors.New("pindxru: некорректная строка списка обновлений")
+)
+
+// HTTPError ответ сервера с кодом, отличным от 200 и 206.
+type HTTPError struct {
+	StatusCode int
+	URL        string
+	// Начало тела ответа
+	BodySnippet string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("pindxru: %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RecordCountError количество записей в архиве не совпадает с указанным в web-справочнике.
+// Например, архив загружен не полностью или по ссылке находится другой архив.
+type RecordCountError struct {
+	URL      string
+	Expected int
+	Actual   int
+}
+
+func (e *RecordCountError) Error() string {
+	return fmt.Sprintf("pindxru: %s: ожидалось записей %d, получено %d", e.URL, e.Expected, e.Actual)
+}
+
+// ChecksumError контрольная сумма загруженного архива не совпадает с ожидаемой.
+type ChecksumError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("pindxru: %s: контрольная сумма SHA-256 %s, ожидалась %s", e.URL, e.Actual, e.Expected)
+}
+
+// RowError ошибка разбора записи dbf-файла.
+type RowError struct {
+	// Номер записи, начиная с 0
+	Row int
+	// Поле записи, если ошибка относится к конкретному полю
+	Field string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("row %d: field %s: %s", e.Row, e.Field, e.Err)
+	}
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors ошибки в нескольких строках. errors.Is и errors.As проверяют каждую ошибку.
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, rowErr := range e {
+		msgs[i] = rowErr.Error()
+	}
+	return fmt.Sprintf("pindxru: ошибок в строках: %d: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e RowErrors) Is(target error) bool {
+	for _, rowErr := range e {
+		if errors.Is(rowErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e RowErrors) As(target interface{}) bool {
+	for _, rowErr := range e {
+		if errors.As(rowErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRowError Оборачивает ошибку в RowError с номером записи row.
+func newRowError(row int, err error) error {
+	var rowErr *RowError
+	if errors.As(err, &rowErr) {
+		rowErr.Row = row
+		return rowErr
+	}
+	return &RowError{Row: row, Err: err}
+}
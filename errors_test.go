@@ -0,0 +1,73 @@
+package pindxru
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowError(t *testing.T) {
+	_, err := createPIndx([]string{"101000"})
+	require.ErrorIs(t, err, ErrFieldCount)
+
+	b := dbftest.MakeDbf(dbftest.CharFields(6, 6, 6, 6, 6, 6, 6, 6, 6, 8, 6), [][]string{
+		{"101000", "", "", "", "", "", "", "", "", "20210120", ""},
+		{"101001", "", "", "", "", "", "", "", "", "2021012X", ""},
+	})
+	dbf, err := newDbfReader(bytes.NewReader(b), fileEncoding)
+	require.Nil(t, err)
+
+	err = dbfEachPIndx(context.Background(), dbf, func(PIndx) error { return nil })
+	var rowErr *RowError
+	require.True(t, errors.As(err, &rowErr))
+	require.Equal(t, 1, rowErr.Row)
+	require.Equal(t, "UpdatedAt", rowErr.Field)
+
+	var parseErr *time.ParseError
+	require.True(t, errors.As(err, &parseErr))
+}
+
+func TestHTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unavailable.zip":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("Service Unavailable"))
+
+		case "/captcha.zip":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>captcha</html>"))
+
+		case "/empty.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write(dbftest.MakeZip("readme.txt", []byte("readme")))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, WithBaseURL(ts.URL))
+	filename := filepath.Join(t.TempDir(), testDbfFile)
+
+	err := c.PackageDbf(Package{Url: ts.URL + "/unavailable.zip"}, filename, os.ModePerm)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	require.Equal(t, ts.URL+"/unavailable.zip", httpErr.URL)
+	require.Equal(t, "Service Unavailable", httpErr.BodySnippet)
+
+	err = c.PackageDbf(Package{Url: ts.URL + "/captcha.zip"}, filename, os.ModePerm)
+	require.ErrorIs(t, err, ErrContentType)
+
+	err = c.PackageDbf(Package{Url: ts.URL + "/empty.zip"}, filename, os.ModePerm)
+	require.ErrorIs(t, err, ErrNoDbfInArchive)
+	require.NoFileExists(t, filename)
+}
@@ -0,0 +1,50 @@
+package pindxru
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+// Пересоздать архивы в testdata: go test -run TestFixtures -update
+var updateFixtures = flag.Bool("update", false, "update testdata fixtures")
+
+// testPIndxRows Записи testdata/PIndx.zip.
+var testPIndxRows = [][]string{
+	{"664700", "ИРКУТСК УФПС", "УФПС", "", "ИРКУТСКАЯ ОБЛАСТЬ", "", "", "ИРКУТСК", "", "20210101", ""},
+	{"664000", "ИРКУТСК ПОЧТАМТ", "ПОЧТАМТ", "664700", "ИРКУТСКАЯ ОБЛАСТЬ", "", "", "ИРКУТСК", "", "20210101", "664000"},
+	{"664001", "ИРКУТСК 1", "ГОПС", "664000", "ИРКУТСКАЯ ОБЛАСТЬ", "", "", "ИРКУТСК", "", "20210101", "664001"},
+	{"664520", "МАРКОВА", "СОПС", "664000", "ИРКУТСКАЯ ОБЛАСТЬ", "", "ИРКУТСКИЙ РАЙОН", "МАРКОВА", "", "20210101", ""},
+	{"628001", "ХАНТЫ-МАНСИЙСК 1", "ГОПС", "628700", "ТЮМЕНСКАЯ ОБЛАСТЬ", "ХАНТЫ-МАНСИЙСКИЙ-ЮГРА АВТОНОМНЫЙ ОКРУГ", "", "ХАНТЫ-МАНСИЙСК", "", "20210101", ""},
+}
+
+// testNPIndxRows Записи testdata/NPIndx.zip.
+var testNPIndxRows = [][]string{
+	{"664001", "664001", "ИРКУТСК 1 ОПС", "ГОПС", "664000", "ИРКУТСКАЯ ОБЛАСТЬ", "", "", "ИРКУТСК", "", "20210115", "664001"},
+	{"664520", "664521", "МАРКОВА", "СОПС", "664000", "ИРКУТСКАЯ ОБЛАСТЬ", "", "ИРКУТСКИЙ РАЙОН", "МАРКОВА", "", "20210118", ""},
+	{"664099", "664099", "ИРКУТСК 99", "ГОПС", "664000", "ИРКУТСКАЯ ОБЛАСТЬ", "", "", "ИРКУТСК", "", "20210120", ""},
+}
+
+func TestFixtures(t *testing.T) {
+	fixtures := map[string][]byte{
+		testPIndxZip:  dbftest.MakeZip("PIndx.dbf", dbftest.MakeDbf(dbftest.PIndxFields, testPIndxRows)),
+		testNPIndxZip: dbftest.MakeZip("NPIndx.dbf", dbftest.MakeDbf(dbftest.NPIndxFields, testNPIndxRows)),
+	}
+
+	for name, content := range fixtures {
+		filename := filepath.Join(testdata, name)
+		if *updateFixtures {
+			require.Nil(t, os.WriteFile(filename, content, 0644))
+			continue
+		}
+
+		b, err := os.ReadFile(filename)
+		require.Nil(t, err)
+		require.True(t, bytes.Equal(content, b), "%s устарел, запустите go test -run TestFixtures -update", filename)
+	}
+}
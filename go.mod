@@ -4,11 +4,14 @@ go 1.18
 
 require github.com/stretchr/testify v1.8.1
 
-require golang.org/x/text v0.4.0
+require golang.org/x/text v0.13.0
+
+require (
+	golang.org/x/net v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/NovikovRoman/godbf v0.2.1
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
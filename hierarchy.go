@@ -0,0 +1,171 @@
+package pindxru
+
+// HierarchyReport Ошибки иерархии подчиненности объектов почтовой связи.
+type HierarchyReport struct {
+	// Циклы подчиненности: индексы объектов от каждого к вышестоящему,
+	// начиная с наименьшего индекса в цикле
+	Cycles [][]string
+	// Объекты, вышестоящего объекта которых (OpsSub) нет в справочнике
+	Orphans []PIndx
+}
+
+// isRoot Является ли объект корнем иерархии: вышестоящий объект не указан или указан сам объект.
+func (p PIndx) isRoot() bool {
+	return p.OpsSub == "" || p.OpsSub == p.Index
+}
+
+// Parent Возвращает вышестоящий объект почтовой связи.
+func (d *Directory) Parent(index string) (parent PIndx, ok bool) {
+	s := d.load()
+
+	var i int
+	if i, ok = s.parent(index); ok {
+		parent = s.items[i]
+	}
+	return
+}
+
+// Children Возвращает непосредственно подчиненные объекты, упорядоченные по индексу.
+func (d *Directory) Children(index string) []PIndx {
+	s := d.load()
+	return s.pick(s.children[index])
+}
+
+// Ancestors Возвращает вышестоящие объекты от непосредственного вышестоящего до корня иерархии.
+// Если иерархия содержит цикл, то каждый объект возвращается один раз.
+func (d *Directory) Ancestors(index string) (ancestors []PIndx) {
+	s := d.load()
+
+	ancestors = []PIndx{}
+	visited := map[string]bool{index: true}
+	for {
+		i, ok := s.parent(index)
+		if !ok || visited[s.items[i].Index] {
+			return
+		}
+
+		index = s.items[i].Index
+		visited[index] = true
+		ancestors = append(ancestors, s.items[i])
+	}
+}
+
+// Descendants Возвращает все подчиненные объекты: сначала непосредственно подчиненные,
+// затем подчиненные им и т.д.
+func (d *Directory) Descendants(index string) (descendants []PIndx) {
+	s := d.load()
+
+	descendants = []PIndx{}
+	visited := map[string]bool{index: true}
+	queue := []string{index}
+	for len(queue) > 0 {
+		for _, i := range s.children[queue[0]] {
+			p := s.items[i]
+			if visited[p.Index] {
+				continue
+			}
+
+			visited[p.Index] = true
+			descendants = append(descendants, p)
+			queue = append(queue, p.Index)
+		}
+		queue = queue[1:]
+	}
+	return
+}
+
+// Roots Возвращает объекты, у которых нет вышестоящего: УФПС, почтамты и т.п.
+// Объекты, вышестоящего объекта которых нет в справочнике, не возвращаются (см. CheckHierarchy).
+func (d *Directory) Roots() (roots []PIndx) {
+	s := d.load()
+
+	roots = []PIndx{}
+	for _, p := range s.items {
+		if p.isRoot() {
+			roots = append(roots, p)
+		}
+	}
+	return
+}
+
+// CheckHierarchy Находит циклы подчиненности и ссылки на отсутствующие вышестоящие объекты.
+func (d *Directory) CheckHierarchy() (report HierarchyReport) {
+	s := d.load()
+
+	const (
+		unvisited = iota
+		inPath
+		done
+	)
+
+	state := make([]int, len(s.items))
+	for start := range s.items {
+		var path []int
+		i := start
+		for state[i] == unvisited {
+			state[i] = inPath
+			path = append(path, i)
+
+			p := s.items[i]
+			if p.isRoot() {
+				break
+			}
+
+			parent, ok := s.byIndex[p.OpsSub]
+			if !ok {
+				report.Orphans = append(report.Orphans, p)
+				break
+			}
+
+			if state[parent] == inPath {
+				report.Cycles = append(report.Cycles, s.cycle(path, parent))
+				break
+			}
+			i = parent
+		}
+
+		for _, j := range path {
+			state[j] = done
+		}
+	}
+	return
+}
+
+// parent Возвращает номер записи вышестоящего объекта.
+func (s *directorySnapshot) parent(index string) (parent int, ok bool) {
+	var i int
+	if i, ok = s.byIndex[index]; !ok {
+		return
+	}
+
+	if p := s.items[i]; !p.isRoot() {
+		parent, ok = s.byIndex[p.OpsSub]
+		return
+	}
+
+	ok = false
+	return
+}
+
+// cycle Возвращает индексы цикла, который в path начинается с записи start.
+func (s *directorySnapshot) cycle(path []int, start int) (cycle []string) {
+	for k, i := range path {
+		if i == start {
+			path = path[k:]
+			break
+		}
+	}
+
+	// записи упорядочены по индексу, поэтому цикл начинается с наименьшего номера
+	first := 0
+	for k, i := range path {
+		if i < path[first] {
+			first = k
+		}
+	}
+
+	for k := range path {
+		cycle = append(cycle, s.items[path[(first+k)%len(path)]].Index)
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package pindxru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testIndexes(indexes []PIndx) (list []string) {
+	list = []string{}
+	for _, p := range indexes {
+		list = append(list, p.Index)
+	}
+	return
+}
+
+func TestDirectory_Hierarchy(t *testing.T) {
+	d := NewDirectory(testReadZip(t, testPIndxZip, ReadPIndxZip))
+
+	parent, ok := d.Parent("664001")
+	require.True(t, ok)
+	require.Equal(t, "664000", parent.Index)
+	_, ok = d.Parent("664700")
+	require.False(t, ok)
+	_, ok = d.Parent("628001")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"664001", "664520"}, testIndexes(d.Children("664000")))
+	require.Equal(t, []string{"664000", "664700"}, testIndexes(d.Ancestors("664520")))
+	require.Equal(t, []string{"664000", "664001", "664520"}, testIndexes(d.Descendants("664700")))
+	require.Empty(t, d.Descendants("664001"))
+	require.Equal(t, []string{"664700"}, testIndexes(d.Roots()))
+
+	report := d.CheckHierarchy()
+	require.Empty(t, report.Cycles)
+	require.Equal(t, []string{"628001"}, testIndexes(report.Orphans))
+
+	// цикл 101000 -> 101002 -> 101001 -> 101000 и объект, подчиненный циклу
+	d = NewDirectory([]PIndx{
+		{Index: "101000", OpsSub: "101002"},
+		{Index: "101001", OpsSub: "101000"},
+		{Index: "101002", OpsSub: "101001"},
+		{Index: "101003", OpsSub: "101002"},
+		{Index: "101004", OpsSub: "101004"},
+	})
+
+	report = d.CheckHierarchy()
+	require.Equal(t, [][]string{{"101000", "101002", "101001"}}, report.Cycles)
+	require.Empty(t, report.Orphans)
+
+	require.Equal(t, []string{"101002", "101001", "101000"}, testIndexes(d.Ancestors("101003")))
+	require.Equal(t, []string{"101002", "101001"}, testIndexes(d.Ancestors("101000")))
+	require.Equal(t, []string{"101001", "101002", "101003"}, testIndexes(d.Descendants("101000")))
+	require.Equal(t, []string{"101004"}, testIndexes(d.Roots()))
+}
@@ -1,74 +1,72 @@
 package pindxru
 
 import (
-	"log"
 	"net/http"
-	"net/url"
+	"net/http/httptest"
 	"os"
-	"time"
-)
+	"path/filepath"
+	"regexp"
+	"testing"
 
-var (
-	cTest             *Client
-	testPackages      []Package
-	testReferenceRows ReferenceRows
+	"github.com/stretchr/testify/require"
 )
 
 const (
 	testdata    = "testdata"
 	testZipFile = "test.zip"
 	testDbfFile = "test.dbf"
-)
-
-func init() {
-	var (
-		transport *http.Transport
-		err       error
-	)
 
-	if transport, err = getTransportTest(); err != nil {
-		log.Fatalln(err)
-	}
+	// testOpsPage страница справочника, которую отдает тестовый сервер.
+	testOpsPage = "ops_2019.html"
+	// testPIndxZip полный справочник, который отдается для любого PIndx[N].zip.
+	testPIndxZip = "PIndx.zip"
+	// testNPIndxZip пакет изменений, который отдается для любого NPIndx[N].zip.
+	testNPIndxZip = "NPIndx.zip"
+)
 
-	cTest = NewClient(transport)
+// testArchiveRe Имя архива в ссылке вида `/documents/10231/6566993295/PIndx01.zip/3473c36a-...`.
+var testArchiveRe = regexp.MustCompile(`/(N?PIndx)\d*\.zip(?:/|$)`)
 
-	if testReferenceRows, err = cTest.GetReferenceRows(); err != nil {
-		log.Fatalln(err)
-	}
+// newTestServer Запускает зеркало web-справочника, которое отдает страницу page и архивы из testdata.
+func newTestServer(t *testing.T, page string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == listUpdatesPath {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			http.ServeFile(w, r, filepath.Join(testdata, page))
+			return
+		}
 
-	d := time.Date(2018, 01, 01, 0, 0, 0, 0, time.UTC)
-	if testPackages, err = testReferenceRows.GetUpdatePackages(&d); err != nil {
-		log.Fatalln(err)
-	}
+		m := testArchiveRe.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
 
-	if len(testPackages) == 0 {
-		log.Fatalln("Нет пакетов изменений.")
-	}
+		w.Header().Set("Content-Type", "application/zip")
+		http.ServeFile(w, r, filepath.Join(testdata, m[1]+".zip"))
+	}))
+	t.Cleanup(ts.Close)
 
-	for _, p := range testPackages {
-		if p.Date.IsZero() || p.NumberRecords <= 0 {
-			log.Fatalln(p, "Ошибочные данные в пакетах.")
-		}
-	}
+	return ts
 }
 
-func getTransportTest() (transport *http.Transport, err error) {
-	var (
-		u     *url.URL
-		proxy string
-	)
+// newTestClient Создает клиента для зеркала web-справочника со страницей testOpsPage.
+func newTestClient(t *testing.T, opts ...Option) (*Client, ReferenceRows) {
+	ts := newTestServer(t, testOpsPage)
+	c := NewClient(nil, append([]Option{WithBaseURL(ts.URL)}, opts...)...)
 
-	if proxy = os.Getenv("PROXY"); proxy == "" {
-		return
-	}
-
-	if u, err = url.Parse(proxy); err != nil {
-		return
-	}
+	rows, err := c.GetReferenceRows()
+	require.Nil(t, err)
+	return c, rows
+}
 
-	transport = &http.Transport{
-		Proxy: http.ProxyURL(u),
-	}
+func testCheckFile(t *testing.T, filename string) {
+	f, err := os.Open(filename)
+	require.Nil(t, err)
+	fi, err := f.Stat()
+	require.Nil(t, err)
+	require.True(t, fi.Size() > 0)
 
-	return
+	require.Nil(t, f.Close())
+	require.Nil(t, os.Remove(filename))
 }
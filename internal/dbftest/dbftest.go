@@ -0,0 +1,108 @@
+// Package dbftest Создает dbf-файлы и zip-архивы для тестов.
+//
+// Пакет не зависит от pindxru, поэтому используется и в тестах pindxru, и в pindxrutest.
+package dbftest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Field Описание поля dbf-файла.
+type Field struct {
+	Name string
+	Type byte
+	Len  int
+}
+
+// PIndxFields Поля полного справочника PIndx.dbf.
+var PIndxFields = []Field{
+	{Name: "INDEX", Type: 'C', Len: 6},
+	{Name: "OPSNAME", Type: 'C', Len: 60},
+	{Name: "OPSTYPE", Type: 'C', Len: 50},
+	{Name: "OPSSUBM", Type: 'C', Len: 6},
+	{Name: "REGION", Type: 'C', Len: 60},
+	{Name: "AUTONOM", Type: 'C', Len: 60},
+	{Name: "AREA", Type: 'C', Len: 60},
+	{Name: "CITY", Type: 'C', Len: 60},
+	{Name: "CITY_1", Type: 'C', Len: 60},
+	{Name: "ACTDATE", Type: 'D', Len: 8},
+	{Name: "INDEXOLD", Type: 'C', Len: 6},
+}
+
+// NPIndxFields Поля пакета изменений NPIndx.dbf.
+var NPIndxFields = []Field{
+	{Name: "INDEX", Type: 'C', Len: 6},
+	{Name: "NEWINDEX", Type: 'C', Len: 6},
+	{Name: "OPSNAME", Type: 'C', Len: 60},
+	{Name: "OPSTYPE", Type: 'C', Len: 50},
+	{Name: "OPSSUBM", Type: 'C', Len: 6},
+	{Name: "REGION", Type: 'C', Len: 60},
+	{Name: "AUTONOM", Type: 'C', Len: 60},
+	{Name: "AREA", Type: 'C', Len: 60},
+	{Name: "CITY", Type: 'C', Len: 60},
+	{Name: "CITY_1", Type: 'C', Len: 60},
+	{Name: "ACTDATE", Type: 'D', Len: 8},
+	{Name: "INDEXOLD", Type: 'C', Len: 6},
+}
+
+// CharFields Символьные поля указанной длины.
+func CharFields(lens ...int) []Field {
+	fields := make([]Field, len(lens))
+	for i, l := range lens {
+		fields[i] = Field{Name: "F" + string(rune('A'+i)), Type: 'C', Len: l}
+	}
+	return fields
+}
+
+// MakeZip Создает zip-архив с одним файлом.
+func MakeZip(name string, content []byte) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, _ := zw.Create(name)
+	_, _ = w.Write(content)
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+// MakeDbf Создает dbf-файл с указанными полями в кодировке CP866.
+func MakeDbf(fields []Field, rows [][]string) []byte {
+	recordLen := 1
+	for _, f := range fields {
+		recordLen += f.Len
+	}
+	headerLen := 32 + 32*len(fields) + 1
+
+	buf := &bytes.Buffer{}
+	header := make([]byte, 32)
+	header[0] = 0x03
+	binary.LittleEndian.PutUint32(header[4:8], uint32(len(rows)))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(headerLen))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(recordLen))
+	buf.Write(header)
+
+	for _, f := range fields {
+		field := make([]byte, 32)
+		copy(field[:10], f.Name)
+		field[11] = f.Type
+		field[16] = byte(f.Len)
+		buf.Write(field)
+	}
+	buf.WriteByte(0x0D)
+
+	encoder := charmap.CodePage866.NewEncoder()
+	for _, row := range rows {
+		buf.WriteByte(' ')
+		for i, f := range fields {
+			value, _ := encoder.Bytes([]byte(row[i]))
+			value = append(value, bytes.Repeat([]byte(" "), f.Len)...)
+			buf.Write(value[:f.Len])
+		}
+	}
+	buf.WriteByte(0x1A)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,83 @@
+package pindxru
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option настройка Client.
+type Option func(*Client)
+
+// WithHTTPClient задает http-клиент. Транспорт, переданный в NewClient, при этом не используется.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithBaseURL задает адрес сайта (зеркала) web-справочника, например, https://www.pochta.ru.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithUserAgent задает заголовок User-Agent для всех запросов.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeaders добавляет заголовки ко всем запросам.
+func WithHeaders(headers http.Header) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = append(c.headers[k], v...)
+		}
+	}
+}
+
+// WithTimeout задает общий таймаут одного запроса.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry задает политику повторов для загрузки страницы справочника и архивов.
+// По умолчанию запросы не повторяются.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithCache включает кэш страницы справочника и архивов в каталоге dir.
+// Повторные запросы выполняются с заголовками If-None-Match и If-Modified-Since,
+// и при ответе 304 Not Modified данные берутся из кэша.
+func WithCache(dir string) Option {
+	return func(c *Client) {
+		c.cache = &httpCache{dir: dir}
+	}
+}
+
+// WithProgress задает функцию для отображения хода загрузки архивов и разбора записей.
+func WithProgress(fn ProgressFunc) Option {
+	return func(c *Client) {
+		c.onProgress = fn
+	}
+}
+
+// WithSource задает источник списка обновлений вместо страницы web-справочника,
+// например, NewManifestSource или NewDirSource.
+func WithSource(source ReferenceSource) Option {
+	return func(c *Client) {
+		c.source = source
+	}
+}
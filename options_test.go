@@ -0,0 +1,47 @@
+package pindxru
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	var (
+		userAgent string
+		header    string
+		path      string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		header = r.Header.Get("X-Test")
+		path = r.URL.Path
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil,
+		WithBaseURL(ts.URL+"/"),
+		WithUserAgent("pindxru-test"),
+		WithHeaders(http.Header{"X-Test": []string{"yes"}}),
+		WithTimeout(time.Second),
+	)
+	require.Equal(t, ts.URL, c.baseURL)
+	require.Equal(t, time.Second, c.httpClient.Timeout)
+
+	_, err := c.GetReferenceRowsContext(context.Background())
+	require.NotNil(t, err)
+	require.Equal(t, "pindxru-test", userAgent)
+	require.Equal(t, "yes", header)
+	require.Equal(t, listUpdatesPath, path)
+
+	hc := &http.Client{}
+	c = NewClient(nil, WithHTTPClient(hc), WithTimeout(time.Second))
+	require.Equal(t, time.Duration(0), hc.Timeout)
+	require.Equal(t, time.Second, c.httpClient.Timeout)
+}
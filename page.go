@@ -0,0 +1,319 @@
+package pindxru
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// pageColumn Колонка таблицы обновлений на странице web-справочника.
+type pageColumn int
+
+const (
+	columnDate pageColumn = iota
+	columnNumber
+	columnUpdate
+	columnFull
+	columnCount
+)
+
+// columnNames Названия колонок для диагностики.
+var columnNames = [columnCount]string{
+	columnDate:   "Дата",
+	columnNumber: "Номер обновления",
+	columnUpdate: "Файл обновлений",
+	columnFull:   "Обновленный эталонный справочник",
+}
+
+var recordsRe = regexp.MustCompile(`(\d[\d\s]*)запис`)
+
+// detectColumn Определяет колонку по тексту заголовка.
+func detectColumn(header string) (column pageColumn, ok bool) {
+	switch {
+	case strings.Contains(header, "файл обновлений"):
+		return columnUpdate, true
+	case strings.Contains(header, "обновленный эталонный справочник"):
+		return columnFull, true
+	case strings.Contains(header, "номер"):
+		return columnNumber, true
+	case strings.HasPrefix(header, "дата"):
+		return columnDate, true
+	}
+	return
+}
+
+// pageTable Таблица обновлений: строки таблицы и номера колонок.
+type pageTable struct {
+	rows    [][]*html.Node
+	columns [columnCount]int
+	found   int
+	// В заголовке упоминаются почтовые индексы, а не, например, справочник ограничений
+	indexes bool
+}
+
+// parseReferenceRows Разбирает таблицу обновлений на странице web-справочника.
+//
+// Таблица определяется по заголовкам колонок, а значения берутся по смыслу колонок,
+// поэтому порядок колонок и разметка ячеек могут меняться. Ссылки на архивы
+// разрешаются относительно pageURL.
+//
+// Если в строках таблицы есть некорректные значения, то возвращает ошибку RowErrors
+// со всеми найденными ошибками и строки без ошибок.
+func parseReferenceRows(b []byte, pageURL string) (referenceRows ReferenceRows, err error) {
+	var (
+		doc  *html.Node
+		base *url.URL
+	)
+
+	if doc, err = html.Parse(bytes.NewReader(b)); err != nil {
+		return
+	}
+
+	if base, err = url.Parse(pageURL); err != nil {
+		return
+	}
+
+	var table *pageTable
+	if table, err = findTable(doc); err != nil {
+		return
+	}
+
+	var rowErrs RowErrors
+	referenceRows = ReferenceRows{}
+	for i, cells := range table.rows {
+		// строки-разделители, например, с годом на всю ширину таблицы
+		if len(cells) <= table.maxColumn() {
+			continue
+		}
+
+		r, errs := parseReferenceRow(cells, table.columns, base)
+		for _, err := range errs {
+			err.Row = i
+			rowErrs = append(rowErrs, err)
+		}
+
+		if len(errs) == 0 {
+			referenceRows = append(referenceRows, r)
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		err = rowErrs
+		return
+	}
+
+	if len(referenceRows) == 0 {
+		err = fmt.Errorf("%w: в таблице обновлений нет строк", ErrPageLayoutChanged)
+	}
+	return
+}
+
+// parseReferenceRow Разбирает строку таблицы обновлений. Возвращает ошибки всех полей строки.
+func parseReferenceRow(cells []*html.Node, columns [columnCount]int, base *url.URL) (r ReferenceRow, errs []*RowError) {
+	var err error
+	if r.Date, err = time.Parse("02.01.2006", nodeText(cells[columns[columnDate]])); err != nil {
+		errs = append(errs, &RowError{Field: "Date", Err: err})
+	}
+
+	if r.Number = nodeText(cells[columns[columnNumber]]); r.Number == "" {
+		errs = append(errs, &RowError{Field: "Number", Err: fmt.Errorf("%w: пустой номер", ErrInvalidReferenceRow)})
+	}
+
+	for _, f := range []struct {
+		name string
+		cell *html.Node
+		file *ReferenceFile
+	}{
+		{"Update", cells[columns[columnUpdate]], &r.Update},
+		{"Full", cells[columns[columnFull]], &r.Full},
+	} {
+		for _, err := range parseReferenceFile(f.cell, base, f.file) {
+			err.Field = f.name + "." + err.Field
+			errs = append(errs, err)
+		}
+	}
+	return
+}
+
+// findTable Находит таблицу обновлений почтовых индексов.
+// Если ни в одной таблице не найдены все колонки, то возвращает ошибку с названиями ненайденных колонок.
+func findTable(doc *html.Node) (table *pageTable, err error) {
+	var best *pageTable
+	for _, n := range findAll(doc, atom.Table) {
+		t := newPageTable(n)
+		if t.found == int(columnCount) && (t.indexes || table == nil) {
+			table = t
+			if t.indexes {
+				return
+			}
+		}
+
+		if best == nil || t.found > best.found {
+			best = t
+		}
+	}
+
+	if table != nil {
+		return
+	}
+
+	if best == nil || best.found == 0 {
+		err = fmt.Errorf("%w: не найдена таблица обновлений", ErrPageLayoutChanged)
+		return
+	}
+
+	var missing []string
+	for c := pageColumn(0); c < columnCount; c++ {
+		if best.columns[c] < 0 {
+			missing = append(missing, "«"+columnNames[c]+"»")
+		}
+	}
+	err = fmt.Errorf("%w: в таблице обновлений не найдены колонки %s", ErrPageLayoutChanged, strings.Join(missing, ", "))
+	return
+}
+
+// newPageTable Определяет колонки таблицы по первой строке, в которой есть хотя бы одна колонка.
+func newPageTable(tableNode *html.Node) (t *pageTable) {
+	t = &pageTable{}
+	for i := range t.columns {
+		t.columns[i] = -1
+	}
+
+	rows := findAll(tableNode, atom.Tr)
+	for i, tr := range rows {
+		cells := rowCells(tr)
+
+		var (
+			columns = t.columns
+			found   int
+			indexes bool
+		)
+		for j, cell := range cells {
+			header := strings.ReplaceAll(strings.ToLower(nodeText(cell)), "ё", "е")
+			if column, ok := detectColumn(header); ok && columns[column] < 0 {
+				columns[column] = j
+				found++
+			}
+			indexes = indexes || strings.Contains(header, "индекс")
+		}
+
+		if found == 0 {
+			continue
+		}
+
+		t.columns = columns
+		t.found = found
+		t.indexes = indexes
+		for _, tr := range rows[i+1:] {
+			t.rows = append(t.rows, rowCells(tr))
+		}
+		return
+	}
+	return
+}
+
+func (t *pageTable) maxColumn() (m int) {
+	for _, c := range t.columns {
+		if c > m {
+			m = c
+		}
+	}
+	return
+}
+
+// parseReferenceFile Разбирает ячейку со ссылкой на архив и количеством записей.
+func parseReferenceFile(cell *html.Node, base *url.URL, f *ReferenceFile) (errs []*RowError) {
+	links := findAll(cell, atom.A)
+	if len(links) == 0 {
+		errs = append(errs, &RowError{Field: "Url", Err: fmt.Errorf("%w: нет ссылки на архив", ErrInvalidReferenceRow)})
+	} else if u, err := base.Parse(strings.TrimSpace(attr(links[0], "href"))); err != nil {
+		errs = append(errs, &RowError{Field: "Url", Err: err})
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		// ссылки вида file:// со страницы позволили бы прочитать локальные файлы
+		errs = append(errs, &RowError{Field: "Url", Err: fmt.Errorf("%w: недопустимая ссылка %s", ErrInvalidReferenceRow, u)})
+	} else {
+		f.Url = u.String()
+	}
+
+	m := recordsRe.FindStringSubmatch(nodeText(cell))
+	if m == nil {
+		errs = append(errs, &RowError{Field: "Records", Err: fmt.Errorf("%w: не указано количество записей", ErrInvalidReferenceRow)})
+		return
+	}
+
+	var err error
+	// число может быть с разделителем разрядов: 42 230 записей
+	if f.Records, err = strconv.Atoi(strings.Join(strings.Fields(m[1]), "")); err != nil {
+		errs = append(errs, &RowError{Field: "Records", Err: err})
+	}
+	return
+}
+
+// rowCells Возвращает ячейки строки таблицы.
+func rowCells(tr *html.Node) (cells []*html.Node) {
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+			cells = append(cells, c)
+		}
+	}
+	return
+}
+
+// findAll Возвращает все элементы a внутри n. Таблицы ищутся на любой глубине, в том числе
+// внутри других таблиц, а остальные элементы - без вложенных таблиц, чтобы строки и ссылки
+// вложенной таблицы не относились к внешней.
+func findAll(n *html.Node, a atom.Atom) (nodes []*html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		if c.DataAtom == a {
+			nodes = append(nodes, c)
+		}
+
+		if c.DataAtom != atom.Table || a == atom.Table {
+			nodes = append(nodes, findAll(c, a)...)
+		}
+	}
+	return
+}
+
+// nodeText Текст элемента с нормализованными пробелами.
+func nodeText(n *html.Node) string {
+	sb := &strings.Builder{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+		case html.ElementNode:
+			if n.DataAtom == atom.Br {
+				sb.WriteByte(' ')
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(strings.ReplaceAll(sb.String(), " ", " ")), " ")
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
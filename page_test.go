@@ -0,0 +1,109 @@
+package pindxru
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseReferenceRows(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join(testdata, "ops_2022.html"))
+	require.Nil(t, err)
+
+	// колонки переставлены, заголовки в thead, есть лишняя колонка и абсолютная ссылка
+	rows, err := parseReferenceRows(b, "https://www.pochta.ru"+listUpdatesPath)
+	require.Nil(t, err)
+	require.Equal(t, ReferenceRows{
+		{
+			Date:   time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			Number: "11",
+			Update: ReferenceFile{Url: "https://files.pochta.ru/documents/NPIndx11.zip", Records: 3},
+			Full:   ReferenceFile{Url: "https://www.pochta.ru/documents/10231/PIndx11.zip", Records: 5},
+		},
+		{
+			Date:   time.Date(2022, 6, 15, 0, 0, 0, 0, time.UTC),
+			Number: "12",
+			Update: ReferenceFile{Url: "https://www.pochta.ru/documents/10231/NPIndx12.zip", Records: 3},
+			Full:   ReferenceFile{Url: "https://www.pochta.ru/documents/10231/PIndx12.zip", Records: 5},
+		},
+	}, rows)
+
+	tests := []struct {
+		name    string
+		page    string
+		message string
+	}{
+		{
+			name:    "без таблицы",
+			page:    `<html><body><p>Страница не найдена</p></body></html>`,
+			message: "не найдена таблица обновлений",
+		},
+		{
+			name: "нет колонки",
+			page: `<table><tr><td>Дата</td><td>Файл обновлений</td><td>Обновленный эталонный справочник</td></tr>
+<tr><td>01.06.2022</td><td><a href="/NPIndx11.zip">NPIndx11.zip</a></td><td><a href="/PIndx11.zip">PIndx11.zip</a></td></tr></table>`,
+			message: "не найдены колонки «Номер обновления»",
+		},
+		{
+			name:    "нет строк",
+			page:    `<table><tr><th>Дата</th><th>Номер</th><th>Файл обновлений</th><th>Обновленный эталонный справочник</th></tr></table>`,
+			message: "нет строк",
+		},
+	}
+
+	// таблица обновлений внутри таблицы разметки страницы
+	page := `<table class="layout"><tr><td class="menu"><a href="/support">Помощь</a></td><td class="content">
+<h1>Эталонный справочник</h1>
+<table><tr><td>Дата</td><td>Номер обновления</td><td>Файл обновлений</td><td>Обновленный эталонный справочник почтовых индексов</td></tr>
+<tr><td>01.06.2022</td><td>11</td><td><a href="/NPIndx11.zip">NPIndx11.zip</a> 3 записи</td><td><a href="/PIndx11.zip">PIndx11.zip</a> 5 записей</td></tr>
+</table>
+</td></tr></table>`
+	rows, err = parseReferenceRows([]byte(page), "https://www.pochta.ru"+listUpdatesPath)
+	require.Nil(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "https://www.pochta.ru/PIndx11.zip", rows[0].Full.Url)
+
+	// ошибки во всех строках, строки без ошибок возвращаются
+	page = `<table><tr><th>Дата</th><th>Номер</th><th>Файл обновлений</th><th>Обновленный эталонный справочник</th></tr>
+<tr><td>2022-06-01</td><td>11</td><td><a href="/NPIndx11.zip">NPIndx11.zip</a> 3 записи</td><td>PIndx11.zip 5 записей</td></tr>
+<tr><td colspan="4">2022</td></tr>
+<tr><td>15.06.2022</td><td>12</td><td><a href="/NPIndx12.zip">NPIndx12.zip</a> 3 записи</td><td><a href="/PIndx12.zip">PIndx12.zip</a> 42 230 записей</td></tr>
+<tr><td>29.06.2022</td><td></td><td><a href="/NPIndx13.zip">NPIndx13.zip</a></td><td><a href="/PIndx13.zip">PIndx13.zip</a> 5 записей</td></tr>
+</table>`
+	rows, err = parseReferenceRows([]byte(page), "https://www.pochta.ru"+listUpdatesPath)
+	require.ErrorIs(t, err, ErrInvalidReferenceRow)
+	require.Len(t, rows, 1)
+	require.Equal(t, "12", rows[0].Number)
+	require.Equal(t, 42230, rows[0].Full.Records)
+
+	var rowErrs RowErrors
+	require.ErrorAs(t, err, &rowErrs)
+	fields := []string{}
+	for _, rowErr := range rowErrs {
+		fields = append(fields, fmt.Sprintf("%d:%s", rowErr.Row, rowErr.Field))
+	}
+	require.Equal(t, []string{"0:Date", "0:Full.Url", "3:Number", "3:Update.Records"}, fields)
+
+	var parseErr *time.ParseError
+	require.ErrorAs(t, err, &parseErr)
+
+	// ссылки только http и https
+	page = `<table><tr><th>Дата</th><th>Номер</th><th>Файл обновлений</th><th>Обновленный эталонный справочник</th></tr>
+<tr><td>01.06.2022</td><td>11</td><td><a href="file:///etc/passwd">NPIndx11.zip</a> 3 записи</td><td><a href="/PIndx11.zip">PIndx11.zip</a> 5 записей</td></tr>
+</table>`
+	_, err = parseReferenceRows([]byte(page), "https://www.pochta.ru"+listUpdatesPath)
+	require.ErrorAs(t, err, &rowErrs)
+	require.Len(t, rowErrs, 1)
+	require.Equal(t, "Update.Url", rowErrs[0].Field)
+	require.ErrorIs(t, err, ErrInvalidReferenceRow)
+
+	for _, tt := range tests {
+		_, err = parseReferenceRows([]byte(tt.page), "https://www.pochta.ru"+listUpdatesPath)
+		require.ErrorIs(t, err, ErrPageLayoutChanged, tt.name)
+		require.Contains(t, err.Error(), tt.message, tt.name)
+	}
+}
@@ -1,7 +1,7 @@
 package pindxru
 
 import (
-	"errors"
+	"fmt"
 	"time"
 )
 
@@ -35,12 +35,13 @@ type PIndx struct {
 
 func createPIndx(data []string) (p PIndx, err error) {
 	if len(data) != 11 {
-		err = errors.New("Полей должно быть 11. ")
+		err = fmt.Errorf("%w: ожидалось 11, получено %d", ErrFieldCount, len(data))
 		return
 	}
 
 	var updatedAt time.Time
 	if updatedAt, err = time.Parse("20060102", data[9]); err != nil {
+		err = &RowError{Field: "UpdatedAt", Err: err}
 		return
 	}
 
@@ -93,13 +94,14 @@ type NPIndx struct {
 
 func createNPIndx(data []string) (p NPIndx, err error) {
 	if len(data) != 12 {
-		err = errors.New("Полей должно быть 12. ")
+		err = fmt.Errorf("%w: ожидалось 12, получено %d", ErrFieldCount, len(data))
 		return
 	}
 
 	var updatedAt time.Time
 	if data[10] != "" {
 		if updatedAt, err = time.Parse("20060102", data[10]); err != nil {
+			err = &RowError{Field: "UpdatedAt", Err: err}
 			return
 		}
 	}
@@ -127,5 +129,12 @@ type Package struct {
 	Date          time.Time
 	Url           string
 	NumberRecords int
-	Indexes       []NPIndx
+	// Контрольная сумма SHA-256 архива (см. ReferenceFile.SHA256)
+	SHA256  string
+	Indexes []NPIndx
+}
+
+// file Возвращает описание архива пакета.
+func (p Package) file() ReferenceFile {
+	return ReferenceFile{Url: p.Url, Records: p.NumberRecords, SHA256: p.SHA256}
 }
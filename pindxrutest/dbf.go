@@ -0,0 +1,49 @@
+package pindxrutest
+
+import (
+	"time"
+
+	"github.com/NovikovRoman/pindxru"
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+)
+
+// PIndxDbf Создает dbf-файл полного справочника в кодировке CP866.
+func PIndxDbf(indexes []pindxru.PIndx) []byte {
+	rows := make([][]string, len(indexes))
+	for i, p := range indexes {
+		rows[i] = []string{
+			p.Index, p.OpsName, p.OpsType, p.OpsSub, p.Region, p.Autonomy,
+			p.Area, p.City, p.SubCity, formatDate(p.UpdatedAt), p.OldIndex,
+		}
+	}
+	return dbftest.MakeDbf(dbftest.PIndxFields, rows)
+}
+
+// NPIndxDbf Создает dbf-файл пакета изменений в кодировке CP866.
+func NPIndxDbf(indexes []pindxru.NPIndx) []byte {
+	rows := make([][]string, len(indexes))
+	for i, p := range indexes {
+		rows[i] = []string{
+			p.Index, p.NewIndex, p.OpsName, p.OpsType, p.OpsSub, p.Region, p.Autonomy,
+			p.Area, p.City, p.SubCity, formatDate(p.UpdatedAt), p.OldIndex,
+		}
+	}
+	return dbftest.MakeDbf(dbftest.NPIndxFields, rows)
+}
+
+// PIndxZip Создает zip-архив с файлом `PIndx.dbf`.
+func PIndxZip(indexes []pindxru.PIndx) []byte {
+	return dbftest.MakeZip("PIndx.dbf", PIndxDbf(indexes))
+}
+
+// NPIndxZip Создает zip-архив с файлом `NPIndx.dbf`.
+func NPIndxZip(indexes []pindxru.NPIndx) []byte {
+	return dbftest.MakeZip("NPIndx.dbf", NPIndxDbf(indexes))
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("20060102")
+}
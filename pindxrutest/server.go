@@ -0,0 +1,244 @@
+// Package pindxrutest Тестовый web-справочник почтовых индексов для проверки кода, использующего pindxru.Client,
+// без доступа к сети.
+//
+//	s := pindxrutest.NewServer(pindxrutest.Release{
+//		Date:   time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC),
+//		Number: "01",
+//		Full:   []pindxru.PIndx{{Index: "101000", UpdatedAt: time.Now()}},
+//	})
+//	defer s.Close()
+//
+//	c := pindxru.NewClient(nil, pindxru.WithBaseURL(s.URL))
+package pindxrutest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NovikovRoman/pindxru"
+)
+
+// OpsPath Путь страницы со списком обновлений.
+const OpsPath = "/support/database/ops"
+
+// Release строка web-справочника: пакет изменений и полный справочник на дату.
+type Release struct {
+	Date   time.Time
+	Number string
+	Update []pindxru.NPIndx
+	Full   []pindxru.PIndx
+}
+
+// Fault Неисправность, которую имитирует Server.
+type Fault int
+
+const (
+	// FaultNone сервер работает без ошибок.
+	FaultNone Fault = iota
+	// FaultServerError на все запросы отдается 500 Internal Server Error.
+	FaultServerError
+	// FaultTruncatedZip архивы отдаются обрезанными наполовину.
+	FaultTruncatedZip
+	// FaultChangedLayout страница справочника отдается без таблицы обновлений.
+	FaultChangedLayout
+)
+
+// Server Тестовый web-справочник. Страница со списком обновлений и архивы
+// формируются из Release при каждом запросе.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	releases []Release
+	fault    Fault
+	failNext int
+	failCode int
+	requests []string
+}
+
+var archiveRe = regexp.MustCompile(`^/documents/(N?PIndx)(.+)\.zip$`)
+
+// NewServer Запускает тестовый web-справочник со строками releases.
+func NewServer(releases ...Release) *Server {
+	s := &Server{releases: releases}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SetReleases Заменяет строки web-справочника.
+func (s *Server) SetReleases(releases ...Release) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = releases
+}
+
+// AddRelease Добавляет строку web-справочника.
+func (s *Server) AddRelease(r Release) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = append(s.releases, r)
+}
+
+// SetFault Включает неисправность f. FaultNone выключает ее.
+func (s *Server) SetFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = f
+}
+
+// FailNext Отвечает кодом statusCode на следующие n запросов.
+func (s *Server) FailNext(n, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+	s.failCode = statusCode
+}
+
+// Requests Возвращает пути всех полученных запросов.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// UpdateURL Возвращает адрес архива пакета изменений строки r.
+func (s *Server) UpdateURL(r Release) string {
+	return s.URL + "/documents/NPIndx" + r.Number + ".zip"
+}
+
+// FullURL Возвращает адрес архива полного справочника строки r.
+func (s *Server) FullURL(r Release) string {
+	return s.URL + "/documents/PIndx" + r.Number + ".zip"
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	releases := append([]Release(nil), s.releases...)
+	fault := s.fault
+
+	failCode := 0
+	if s.failNext > 0 {
+		s.failNext--
+		failCode = s.failCode
+	}
+	s.mu.Unlock()
+
+	if failCode != 0 {
+		http.Error(w, http.StatusText(failCode), failCode)
+		return
+	}
+
+	if fault == FaultServerError {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Path == OpsPath {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if fault == FaultChangedLayout {
+			_, _ = w.Write([]byte(changedLayoutPage))
+			return
+		}
+		_, _ = w.Write([]byte(s.page(releases)))
+		return
+	}
+
+	m := archiveRe.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var b []byte
+	for _, release := range releases {
+		if release.Number != m[2] {
+			continue
+		}
+
+		if m[1] == "PIndx" {
+			b = PIndxZip(release.Full)
+		} else {
+			b = NPIndxZip(release.Update)
+		}
+		break
+	}
+
+	if b == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if fault == FaultTruncatedZip {
+		b = b[:len(b)/2]
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	_, _ = w.Write(b)
+}
+
+// page Формирует страницу справочника в разметке pochta.ru.
+func (s *Server) page(releases []Release) string {
+	sb := &strings.Builder{}
+	sb.WriteString(`<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>Эталонный справочник почтовых индексов объектов почтовой связи</title></head>
+<body>
+<table class="table">
+<tbody>
+<tr>
+<td>Дата</td>
+<td>Номер обновления</td>
+<td>Файл обновлений эталонного справочника почтовых индексов объектов почтовой связи</td>
+<td>Обновленный эталонный справочник почтовых индексов объектов почтовой связи</td>
+</tr>
+`)
+
+	for _, r := range releases {
+		fmt.Fprintf(sb, `<tr><td>%s</td>
+<td>%s</td>
+<td><a href="%s">NPIndx%s.zip</a><br><br>1 КБ,&nbsp;%d %s</td>
+<td><a href="%s">PIndx%s.zip</a><br><br>748 КБ,&nbsp;%d %s</td>
+</tr>
+`,
+			r.Date.Format("02.01.2006"),
+			html.EscapeString(r.Number),
+			html.EscapeString(strings.TrimPrefix(s.UpdateURL(r), s.URL)), html.EscapeString(r.Number),
+			len(r.Update), records(len(r.Update)),
+			html.EscapeString(strings.TrimPrefix(s.FullURL(r), s.URL)), html.EscapeString(r.Number),
+			len(r.Full), records(len(r.Full)),
+		)
+	}
+
+	sb.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+// records Склоняет слово «запись» для числа n.
+func records(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return "записей"
+	case n%10 == 1:
+		return "запись"
+	case n%10 >= 2 && n%10 <= 4:
+		return "записи"
+	default:
+		return "записей"
+	}
+}
+
+const changedLayoutPage = `<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>Эталонный справочник</title></head>
+<body>
+<div class="support-article-root">Справочник временно недоступен</div>
+</body>
+</html>
+`
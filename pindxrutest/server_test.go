@@ -0,0 +1,93 @@
+package pindxrutest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NovikovRoman/pindxru"
+	"github.com/stretchr/testify/require"
+)
+
+func testReleases() []Release {
+	d := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+	return []Release{
+		{
+			Date:   d,
+			Number: "01",
+			Update: []pindxru.NPIndx{
+				{Index: "101000", NewIndex: "101000", OpsName: "МОСКВА 101", Region: "МОСКВА", UpdatedAt: d},
+			},
+			Full: []pindxru.PIndx{
+				{Index: "101000", OpsName: "МОСКВА 101", Region: "МОСКВА", UpdatedAt: d},
+				{Index: "664000", OpsName: "ИРКУТСК ПОЧТАМТ", Region: "ИРКУТСКАЯ ОБЛАСТЬ", UpdatedAt: d},
+			},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer(testReleases()...)
+	defer s.Close()
+
+	c := pindxru.NewClient(nil, pindxru.WithBaseURL(s.URL))
+	rows, err := c.GetReferenceRows()
+	require.Nil(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "01", rows[0].Number)
+	require.Equal(t, s.FullURL(testReleases()[0]), rows[0].Full.Url)
+	require.Equal(t, 2, rows[0].Full.Records)
+	require.Equal(t, 1, rows[0].Update.Records)
+
+	indexes, _, err := c.Indexes(rows, nil)
+	require.Nil(t, err)
+	require.Equal(t, []pindxru.PIndx{
+		{Index: "101000", OpsName: "МОСКВА 101", Region: "МОСКВА", UpdatedAt: rows[0].Date, RegionCode: 77},
+		{Index: "664000", OpsName: "ИРКУТСК ПОЧТАМТ", Region: "ИРКУТСКАЯ ОБЛАСТЬ", UpdatedAt: rows[0].Date, RegionCode: 38},
+	}, indexes)
+
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+	_, err = c.GetPackageIndexes(&packages[0])
+	require.Nil(t, err)
+	require.Len(t, packages[0].Indexes, 1)
+	require.Equal(t, "101000", packages[0].Indexes[0].NewIndex)
+
+	s.AddRelease(Release{Date: rows[0].Date.AddDate(0, 1, 0), Number: "02"})
+	rows, err = c.GetReferenceRows()
+	require.Nil(t, err)
+	require.Len(t, rows, 2)
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := NewServer(testReleases()...)
+	defer s.Close()
+
+	c := pindxru.NewClient(nil, pindxru.WithBaseURL(s.URL))
+	rows, err := c.GetReferenceRows()
+	require.Nil(t, err)
+
+	s.SetFault(FaultServerError)
+	_, err = c.GetReferenceRows()
+	var httpErr *pindxru.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+
+	s.SetFault(FaultChangedLayout)
+	_, err = c.GetReferenceRows()
+	require.ErrorIs(t, err, pindxru.ErrPageLayoutChanged)
+
+	s.SetFault(FaultTruncatedZip)
+	_, _, err = c.Indexes(rows, nil)
+	require.NotNil(t, err)
+
+	s.SetFault(FaultNone)
+	s.FailNext(2, http.StatusServiceUnavailable)
+	policy := pindxru.DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	c = pindxru.NewClient(nil, pindxru.WithBaseURL(s.URL), pindxru.WithRetry(policy))
+	_, _, err = c.IndexesContext(context.Background(), rows, nil)
+	require.Nil(t, err)
+}
@@ -0,0 +1,49 @@
+package pindxru
+
+import "io"
+
+// Этапы, о ходе которых сообщает ProgressFunc.
+const (
+	// ProgressDownload загружено байт ответа сервера.
+	ProgressDownload = "download"
+	// ProgressInflate распаковано байт dbf-файла из zip-архива.
+	ProgressInflate = "inflate"
+	// ProgressRows разобрано записей dbf-файла.
+	ProgressRows = "rows"
+)
+
+// ProgressFunc получает ход выполнения этапа stage: сколько выполнено и сколько всего.
+// Если общий объем неизвестен (нет Content-Length), то total равен -1.
+type ProgressFunc func(stage string, done, total int64)
+
+// progressReader Сообщает о количестве прочитанных байт.
+type progressReader struct {
+	io.ReadCloser
+	stage string
+	done  int64
+	total int64
+	fn    ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 {
+		r.done += int64(n)
+		r.fn(r.stage, r.done, r.total)
+	}
+	return
+}
+
+// rowsProgress Возвращает функцию, которую нужно вызывать после разбора каждой записи dbf-файла.
+func (c Client) rowsProgress(dbf *dbfReader) func() {
+	if c.onProgress == nil {
+		return func() {}
+	}
+
+	var done int64
+	total := int64(dbf.NumberOfRecords())
+	return func() {
+		done++
+		c.onProgress(ProgressRows, done, total)
+	}
+}
@@ -0,0 +1,39 @@
+package pindxru
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithProgress(t *testing.T) {
+	dbf := dbftest.MakeDbf(dbftest.CharFields(6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 8, 6), [][]string{
+		{"101000", "101000", "", "", "", "", "", "", "", "", "20210120", ""},
+		{"101001", "101001", "", "", "", "", "", "", "", "", "20210121", ""},
+	})
+	content := dbftest.MakeZip("NPIndx01.dbf", dbf)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer ts.Close()
+
+	last := map[string][2]int64{}
+	c := NewClient(nil, WithProgress(func(stage string, done, total int64) {
+		require.True(t, done > last[stage][0])
+		last[stage] = [2]int64{done, total}
+	}))
+
+	pack := &Package{Url: ts.URL}
+	_, err := c.GetPackageIndexesContext(context.Background(), pack)
+	require.Nil(t, err)
+	require.Len(t, pack.Indexes, 2)
+
+	require.Equal(t, [2]int64{int64(len(content)), int64(len(content))}, last[ProgressDownload])
+	require.Equal(t, [2]int64{int64(len(dbf)), int64(len(dbf))}, last[ProgressInflate])
+	require.Equal(t, [2]int64{2, 2}, last[ProgressRows])
+}
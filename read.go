@@ -0,0 +1,104 @@
+package pindxru
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+)
+
+// ReadPIndxZip Читает почтовые индексы из zip-файла полного справочника, например, сохраненного
+// с помощью IndexesZip или загруженного вручную.
+func ReadPIndxZip(r io.ReaderAt, size int64) (indexes []PIndx, err error) {
+	var rc io.ReadCloser
+	if rc, err = openZipDbf(r, size); err != nil {
+		return
+	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	return ReadPIndxDbf(rc)
+}
+
+// ReadPIndxDbf Читает почтовые индексы из dbf-файла полного справочника, например, сохраненного
+// с помощью IndexesDbf.
+func ReadPIndxDbf(r io.Reader) (indexes []PIndx, err error) {
+	var dbf *dbfReader
+	if dbf, err = newDbfReader(r, fileEncoding); err != nil {
+		return
+	}
+
+	indexes = []PIndx{}
+	err = dbfEachPIndx(context.Background(), dbf, func(p PIndx) error {
+		indexes = append(indexes, p)
+		return nil
+	})
+	if err != nil {
+		indexes = nil
+	}
+	return
+}
+
+// ReadNPIndxZip Читает изменения из zip-файла пакета изменений, например, сохраненного
+// с помощью PackageZip или загруженного вручную.
+func ReadNPIndxZip(r io.ReaderAt, size int64) (indexes []NPIndx, err error) {
+	var rc io.ReadCloser
+	if rc, err = openZipDbf(r, size); err != nil {
+		return
+	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	return ReadNPIndxDbf(rc)
+}
+
+// ReadNPIndxDbf Читает изменения из dbf-файла пакета изменений, например, сохраненного
+// с помощью PackageDbf.
+func ReadNPIndxDbf(r io.Reader) (indexes []NPIndx, err error) {
+	var dbf *dbfReader
+	if dbf, err = newDbfReader(r, fileEncoding); err != nil {
+		return
+	}
+
+	indexes = []NPIndx{}
+	err = dbfEachNPIndx(context.Background(), dbf, func(p NPIndx) error {
+		indexes = append(indexes, p)
+		return nil
+	})
+	if err != nil {
+		indexes = nil
+	}
+	return
+}
+
+// openZipDbf Открывает для чтения dbf-файл из zip-файла (см. findDbf).
+func openZipDbf(r io.ReaderAt, size int64) (rc io.ReadCloser, err error) {
+	var zipFile *zip.File
+	if zipFile, err = findDbf(r, size); err != nil {
+		return
+	}
+	return zipFile.Open()
+}
+
+// findDbf Находит в zip-файле dbf-файл с именем `PIndx[N].dbf` или `NPIndx[N].dbf`, где N - целое число.
+func findDbf(r io.ReaderAt, size int64) (zipFile *zip.File, err error) {
+	var zipReader *zip.Reader
+	if zipReader, err = zip.NewReader(r, size); err != nil {
+		return
+	}
+
+	for _, zipFile = range zipReader.File {
+		if dbfNameRe.MatchString(zipFile.Name) {
+			return
+		}
+	}
+
+	zipFile = nil
+	err = ErrNoDbfInArchive
+	return
+}
@@ -0,0 +1,78 @@
+package pindxru
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPIndx(t *testing.T) {
+	c, rows := newTestClient(t)
+	dir := t.TempDir()
+	zipFile := filepath.Join(dir, testZipFile)
+	dbfFile := filepath.Join(dir, testDbfFile)
+
+	_, _, err := c.IndexesZip(rows, zipFile, 0666, nil)
+	require.Nil(t, err)
+	_, _, err = c.IndexesDbf(rows, dbfFile, 0666, nil)
+	require.Nil(t, err)
+
+	expected, _, err := c.Indexes(rows, nil)
+	require.Nil(t, err)
+
+	b, err := os.ReadFile(zipFile)
+	require.Nil(t, err)
+	indexes, err := ReadPIndxZip(bytes.NewReader(b), int64(len(b)))
+	require.Nil(t, err)
+	require.Equal(t, expected, indexes)
+
+	f, err := os.Open(dbfFile)
+	require.Nil(t, err)
+	defer func() {
+		require.Nil(t, f.Close())
+	}()
+	indexes, err = ReadPIndxDbf(f)
+	require.Nil(t, err)
+	require.Equal(t, expected, indexes)
+
+	// пакет изменений вместо полного справочника
+	b, err = os.ReadFile(filepath.Join(testdata, testNPIndxZip))
+	require.Nil(t, err)
+	_, err = ReadPIndxZip(bytes.NewReader(b), int64(len(b)))
+	require.ErrorIs(t, err, ErrFieldCount)
+
+	b = dbftest.MakeZip("readme.txt", []byte("-"))
+	_, err = ReadPIndxZip(bytes.NewReader(b), int64(len(b)))
+	require.ErrorIs(t, err, ErrNoDbfInArchive)
+}
+
+func TestReadNPIndx(t *testing.T) {
+	c, rows := newTestClient(t)
+	dbfFile := filepath.Join(t.TempDir(), testDbfFile)
+
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+	require.Nil(t, c.PackageDbf(packages[0], dbfFile, 0666))
+	_, err = c.GetPackageIndexes(&packages[0])
+	require.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(testdata, testNPIndxZip))
+	require.Nil(t, err)
+	indexes, err := ReadNPIndxZip(bytes.NewReader(b), int64(len(b)))
+	require.Nil(t, err)
+	require.Equal(t, packages[0].Indexes, indexes)
+
+	b, err = os.ReadFile(dbfFile)
+	require.Nil(t, err)
+	indexes, err = ReadNPIndxDbf(bytes.NewReader(b))
+	require.Nil(t, err)
+	require.Equal(t, packages[0].Indexes, indexes)
+
+	_, err = ReadNPIndxDbf(bytes.NewReader(b[:len(b)-10]))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
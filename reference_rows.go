@@ -1,6 +1,11 @@
 package pindxru
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,35 +19,33 @@ type ReferenceRow struct {
 type ReferenceFile struct {
 	Url     string
 	Records int
+	// Контрольная сумма SHA-256 архива в шестнадцатеричном виде. Если указана, то загруженный архив
+	// сверяется с ней, иначе заполняется после загрузки.
+	SHA256 string
 }
 
 type ReferenceRows []ReferenceRow
 
 // GetLastModified Возвращает дату последнего обновления из web-справочника.
 func (r ReferenceRows) GetLastModified() (lastMod time.Time, err error) {
-	if len(r) == 0 {
-		return
+	if latest := r.Latest(); latest != nil {
+		lastMod = latest.Date
 	}
-
-	lastMod = r[len(r)-1].Date
 	return
 }
 
-// LastRow Возвращает последнюю строку.
+// LastRow Возвращает строку последнего обновления независимо от порядка строк.
 func (r ReferenceRows) LastRow() (referenceRow *ReferenceRow, err error) {
-	if len(r) == 0 {
-		return
-	}
-
-	referenceRow = &r[len(r)-1]
+	referenceRow = r.Latest()
 	return
 }
 
 // GetUpdatePackages Возвращает список обновлений начиная от даты >= lastModified.
+// Обновления упорядочены по дате.
 func (r ReferenceRows) GetUpdatePackages(lastModified *time.Time) (packages []Package, err error) {
 	packages = []Package{}
 
-	for _, rr := range r {
+	for _, rr := range r.sorted() {
 		if lastModified != nil && (rr.Date.Before(*lastModified) || rr.Date.Equal(*lastModified)) {
 			continue
 		}
@@ -51,6 +54,7 @@ func (r ReferenceRows) GetUpdatePackages(lastModified *time.Time) (packages []Pa
 			Date:          rr.Date,
 			Url:           rr.Update.Url,
 			NumberRecords: rr.Update.Records,
+			SHA256:        rr.Update.SHA256,
 			Indexes:       []NPIndx{},
 		})
 	}
@@ -58,6 +62,101 @@ func (r ReferenceRows) GetUpdatePackages(lastModified *time.Time) (packages []Pa
 	return
 }
 
+// Sort Упорядочивает строки по дате, а строки с одинаковой датой - по номеру обновления.
+func (r ReferenceRows) Sort() {
+	sort.SliceStable(r, func(i, j int) bool {
+		if !r[i].Date.Equal(r[j].Date) {
+			return r[i].Date.Before(r[j].Date)
+		}
+		return compareNumbers(r[i].Number, r[j].Number) < 0
+	})
+}
+
+// compareNumbers Сравнивает номера обновлений: как числа, если оба номера числовые, иначе как строки.
+func compareNumbers(a, b string) int {
+	x, xerr := strconv.Atoi(a)
+	y, yerr := strconv.Atoi(b)
+	switch {
+	case xerr != nil || yerr != nil:
+		return strings.Compare(a, b)
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// sorted Возвращает упорядоченную копию строк.
+func (r ReferenceRows) sorted() (rows ReferenceRows) {
+	rows = append(ReferenceRows{}, r...)
+	rows.Sort()
+	return
+}
+
+// ByNumber Возвращает строку с номером обновления n или nil.
+func (r ReferenceRows) ByNumber(n string) *ReferenceRow {
+	for i := range r {
+		if r[i].Number == n {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// ByDate Возвращает строку обновления за день t или nil.
+func (r ReferenceRows) ByDate(t time.Time) *ReferenceRow {
+	y, m, d := t.Date()
+	for i := range r {
+		if ry, rm, rd := r[i].Date.Date(); ry == y && rm == m && rd == d {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// Between Возвращает упорядоченные по дате строки с датой from <= Date <= to.
+func (r ReferenceRows) Between(from, to time.Time) (rows ReferenceRows) {
+	rows = ReferenceRows{}
+	for _, rr := range r.sorted() {
+		if !rr.Date.Before(from) && !rr.Date.After(to) {
+			rows = append(rows, rr)
+		}
+	}
+	return
+}
+
+// Latest Возвращает строку с самой поздней датой или nil, если строк нет.
+// Из строк с одинаковой датой выбирается строка с большим номером, как последняя после Sort.
+func (r ReferenceRows) Latest() (latest *ReferenceRow) {
+	for i := range r {
+		if latest == nil || r[i].Date.After(latest.Date) ||
+			r[i].Date.Equal(latest.Date) && compareNumbers(r[i].Number, latest.Number) >= 0 {
+			latest = &r[i]
+		}
+	}
+	return
+}
+
+// lastUpdate Возвращает последнюю строку, если она новее lastMod или lastMod не указана.
+func (r ReferenceRows) lastUpdate(lastMod *time.Time) (row *ReferenceRow, ok bool, err error) {
+	if len(r) == 0 {
+		return
+	}
+
+	if lastMod != nil {
+		if ok, err = r.hasUpdates(*lastMod); err != nil || !ok {
+			return
+		}
+	}
+
+	if row, err = r.LastRow(); err != nil {
+		return
+	}
+	ok = row != nil
+	return
+}
+
 // hasUpdates Есть ли обновление.
 func (r ReferenceRows) hasUpdates(lastModified time.Time) (ok bool, err error) {
 	var lastMod time.Time
@@ -69,3 +168,53 @@ func (r ReferenceRows) hasUpdates(lastModified time.Time) (ok bool, err error) {
 	ok = !(lastModified.Equal(lastMod) || lastModified.After(lastMod))
 	return
 }
+
+// Validate Проверяет строки: даты возрастают, количество записей положительное,
+// ссылки абсолютные, номера обновлений не повторяются.
+// Возвращает RowErrors со всеми найденными ошибками.
+func (r ReferenceRows) Validate() (err error) {
+	var rowErrs RowErrors
+	addErr := func(row int, field, format string, a ...interface{}) {
+		rowErrs = append(rowErrs, &RowError{
+			Row:   row,
+			Field: field,
+			Err:   fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidReferenceRow}, a...)...),
+		})
+	}
+
+	numbers := map[string]int{}
+	for i, rr := range r {
+		if rr.Date.IsZero() {
+			addErr(i, "Date", "не указана дата")
+		} else if i > 0 && !r[i-1].Date.IsZero() && !rr.Date.After(r[i-1].Date) {
+			addErr(i, "Date", "дата %s не больше даты предыдущей строки %s",
+				rr.Date.Format("02.01.2006"), r[i-1].Date.Format("02.01.2006"))
+		}
+
+		if rr.Number == "" {
+			addErr(i, "Number", "не указан номер обновления")
+		} else if j, ok := numbers[rr.Number]; ok {
+			addErr(i, "Number", "номер %s совпадает с номером строки %d", rr.Number, j)
+		} else {
+			numbers[rr.Number] = i
+		}
+
+		for _, f := range []struct {
+			name string
+			file ReferenceFile
+		}{{"Update", rr.Update}, {"Full", rr.Full}} {
+			if f.file.Records <= 0 {
+				addErr(i, f.name+".Records", "количество записей %d", f.file.Records)
+			}
+
+			if u, err := url.Parse(f.file.Url); err != nil || !u.IsAbs() {
+				addErr(i, f.name+".Url", "ссылка %q не абсолютная", f.file.Url)
+			}
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		err = rowErrs
+	}
+	return
+}
@@ -1,14 +1,126 @@
 package pindxru
 
 import (
-	"github.com/stretchr/testify/require"
+	"fmt"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func Test_GetLastModified(t *testing.T) {
-	d, err := testReferenceRows.GetLastModified()
+	_, rows := newTestClient(t)
+
+	d, err := rows.GetLastModified()
 	require.Nil(t, err)
 	require.IsType(t, d, time.Time{})
-	require.False(t, d.IsZero())
+	require.Equal(t, time.Date(2021, 2, 16, 0, 0, 0, 0, time.UTC), d)
+
+	d, err = ReferenceRows{}.GetLastModified()
+	require.Nil(t, err)
+	require.True(t, d.IsZero())
+}
+
+func TestReferenceRows_GetUpdatePackages(t *testing.T) {
+	_, rows := newTestClient(t)
+
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+	require.Len(t, packages, 3)
+
+	d := time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC)
+	packages, err = rows.GetUpdatePackages(&d)
+	require.Nil(t, err)
+	require.Len(t, packages, 1)
+	require.Equal(t, time.Date(2021, 2, 16, 0, 0, 0, 0, time.UTC), packages[0].Date)
+	require.Equal(t, 3, packages[0].NumberRecords)
+	require.Contains(t, packages[0].Url, "NPIndx03.zip")
+
+	// (!) обновлений нет
+	d = time.Now().Add(time.Hour * 1000)
+	packages, err = rows.GetUpdatePackages(&d)
+	require.Nil(t, err)
+	require.Len(t, packages, 0)
+}
+
+func TestReferenceRows_Validate(t *testing.T) {
+	_, rows := newTestClient(t)
+	require.Nil(t, rows.Validate())
+	require.Nil(t, ReferenceRows{}.Validate())
+
+	invalid := append(ReferenceRows{}, rows...)
+	invalid[1].Date = invalid[0].Date
+	invalid[2].Number = invalid[0].Number
+	invalid[2].Full.Records = 0
+	invalid[2].Update.Url = "/documents/NPIndx03.zip"
+
+	err := invalid.Validate()
+	require.ErrorIs(t, err, ErrInvalidReferenceRow)
+
+	var rowErrs RowErrors
+	require.ErrorAs(t, err, &rowErrs)
+
+	fields := []string{}
+	for _, rowErr := range rowErrs {
+		fields = append(fields, fmt.Sprintf("%d:%s", rowErr.Row, rowErr.Field))
+	}
+	require.Equal(t, []string{"1:Date", "2:Number", "2:Update.Url", "2:Full.Records"}, fields)
+}
+
+func TestReferenceRows_Sort(t *testing.T) {
+	_, rows := newTestClient(t)
+
+	// страница со списком от новых к старым
+	reversed := ReferenceRows{rows[2], rows[0], rows[1]}
+	latest := reversed.Latest()
+	require.Equal(t, "03", latest.Number)
+
+	row, err := reversed.LastRow()
+	require.Nil(t, err)
+	require.Equal(t, "03", row.Number)
+
+	d, err := reversed.GetLastModified()
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2021, 2, 16, 0, 0, 0, 0, time.UTC), d)
+
+	from := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+	packages, err := reversed.GetUpdatePackages(&from)
+	require.Nil(t, err)
+	require.Len(t, packages, 2)
+	require.Contains(t, packages[0].Url, "NPIndx02.zip")
+	require.Contains(t, packages[1].Url, "NPIndx03.zip")
+
+	between := reversed.Between(from, time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC))
+	require.Len(t, between, 2)
+	require.Equal(t, "01", between[0].Number)
+	require.Equal(t, "02", between[1].Number)
+	require.Len(t, reversed.Between(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Now()), 0)
+
+	require.Equal(t, rows[1].Date, reversed.ByNumber("02").Date)
+	require.Nil(t, reversed.ByNumber("99"))
+	require.Equal(t, "02", reversed.ByDate(time.Date(2021, 2, 4, 15, 30, 0, 0, time.UTC)).Number)
+	require.Nil(t, reversed.ByDate(time.Date(2021, 2, 5, 0, 0, 0, 0, time.UTC)))
+
+	require.Equal(t, "03", reversed[0].Number)
+	reversed.Sort()
+	require.Equal(t, rows, reversed)
+
+	require.Nil(t, ReferenceRows{}.Latest())
+
+	// две строки за одну дату
+	sameDate := ReferenceRows{rows[2], rows[1]}
+	sameDate[1].Date = sameDate[0].Date
+	require.Equal(t, "03", sameDate.Latest().Number)
+	sameDate[0], sameDate[1] = sameDate[1], sameDate[0]
+	require.Equal(t, "03", sameDate.Latest().Number)
+	sameDate.Sort()
+	require.Equal(t, sameDate[len(sameDate)-1], *sameDate.Latest())
+
+	// номера без ведущих нулей сравниваются как числа
+	d = time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC)
+	unpadded := ReferenceRows{{Date: d, Number: "10"}, {Date: d, Number: "9"}}
+	require.Equal(t, "10", unpadded.Latest().Number)
+	unpadded.Sort()
+	require.Equal(t, "9", unpadded[0].Number)
+	require.Equal(t, "10", unpadded[1].Number)
 }
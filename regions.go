@@ -1,7 +1,7 @@
 package pindxru
 
 import (
-	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -136,14 +136,14 @@ var Regions = regions{
 
 func FindRegionCodeByIndex(index string) (code int, err error) {
 	if len(index) < 3 {
-		err = errors.New("Minimum 3 digits required. ")
+		err = fmt.Errorf("%w: требуется минимум 3 цифры", ErrInvalidIndex)
 		return
 	}
 	var ok bool
 	if code, ok = postalCodes[index[0:3]]; ok {
 		return
 	}
-	err = errors.New("Not found. ")
+	err = ErrRegionNotFound
 	return
 }
 
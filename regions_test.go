@@ -49,7 +49,11 @@ func TestRegions_FindCode(t *testing.T) {
 
 func TestRegions_FindRegionCodeByIndex(t *testing.T) {
 	code, err := FindRegionCodeByIndex("Тюменская область")
-	require.NotNil(t, err)
+	require.ErrorIs(t, err, ErrRegionNotFound)
+	require.Equal(t, code, 0)
+
+	code, err = FindRegionCodeByIndex("16")
+	require.ErrorIs(t, err, ErrInvalidIndex)
 	require.Equal(t, code, 0)
 
 	code, err = FindRegionCodeByIndex("165")
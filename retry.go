@@ -0,0 +1,138 @@
+package pindxru
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy политика повторов запросов к web-справочнику.
+type RetryPolicy struct {
+	// Максимальное количество попыток, включая первую. Значение меньше 2 отключает повторы.
+	MaxAttempts int
+	// Задержка перед первым повтором. Для каждого следующего повтора задержка удваивается.
+	MinBackoff time.Duration
+	// Максимальная задержка между попытками, в том числе заданная сервером в Retry-After.
+	MaxBackoff time.Duration
+	// Коды ответа, при которых запрос повторяется. Из сетевых ошибок повторяются таймауты,
+	// разрыв и отказ в соединении, а также преждевременный конец ответа.
+	RetryableStatuses []int
+	// Вызывается перед каждым повтором: номер неудачной попытки, задержка и ошибка.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy Политика повторов с разумными значениями.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+		RetryableStatuses: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// retryable Можно ли повторить запрос после ошибки err.
+func (p RetryPolicy) retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		for _, code := range p.RetryableStatuses {
+			if code == httpErr.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// backoff Задержка после неудачной попытки attempt: экспоненциальная, со случайным разбросом в пределах половины.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if half := int64(d / 2); half > 0 {
+		jitterMu.Lock()
+		d = time.Duration(half + jitterRand.Int63n(half+1))
+		jitterMu.Unlock()
+	}
+	return d
+}
+
+// wait Задержка перед повтором после неудачной попытки attempt с учетом Retry-After,
+// но не больше MaxBackoff.
+func (p RetryPolicy) wait(attempt int, retryAfter time.Duration) (d time.Duration) {
+	d = p.backoff(attempt)
+	if retryAfter > d {
+		d = retryAfter
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return
+}
+
+// parseRetryAfter Разбирает заголовок Retry-After: количество секунд или дата.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// sleep Ожидает d или отмены контекста.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
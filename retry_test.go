@@ -0,0 +1,127 @@
+package pindxru
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/not-found.zip":
+			w.WriteHeader(http.StatusNotFound)
+
+		case requests < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+		default:
+			_, _ = w.Write(dbftest.MakeZip("NPIndx01.dbf", dbftest.MakeDbf(dbftest.CharFields(6), nil)))
+		}
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 2 * time.Millisecond
+	policy.OnRetry = func(attempt int, wait time.Duration, err error) {
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		require.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		waits = append(waits, wait)
+	}
+
+	c := NewClient(nil, WithRetry(policy))
+	f, err := c.downloadZip(context.Background(), ts.URL+"/package.zip")
+	require.Nil(t, err)
+	removeTempFile(f)
+	require.Equal(t, 3, requests)
+	require.Len(t, waits, 2)
+	for _, w := range waits {
+		require.True(t, w <= policy.MaxBackoff)
+	}
+
+	// 404 не повторяется
+	requests = 0
+	_, err = c.downloadZip(context.Background(), ts.URL+"/not-found.zip")
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, 1, requests)
+
+	// без политики повторов
+	requests = 0
+	_, err = NewClient(nil).downloadZip(context.Background(), ts.URL+"/package.zip")
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, 1, requests)
+}
+
+func TestRetryPolicy_retryable(t *testing.T) {
+	p := DefaultRetryPolicy()
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://www.pochta.ru/", Err: err}
+	}
+
+	require.True(t, p.retryable(&HTTPError{StatusCode: http.StatusServiceUnavailable}))
+	require.False(t, p.retryable(&HTTPError{StatusCode: http.StatusNotFound}))
+	require.True(t, p.retryable(urlErr(os.ErrDeadlineExceeded)))
+	require.True(t, p.retryable(urlErr(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)})))
+	require.True(t, p.retryable(urlErr(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)})))
+	require.True(t, p.retryable(urlErr(io.ErrUnexpectedEOF)))
+	require.False(t, p.retryable(urlErr(x509.UnknownAuthorityError{})))
+	require.False(t, p.retryable(urlErr(errors.New("unsupported protocol scheme"))))
+	require.False(t, p.retryable(urlErr(errors.New("stopped after 10 redirects"))))
+
+	// неподдерживаемая схема не повторяется
+	p.OnRetry = func(int, time.Duration, error) {
+		t.Error("unexpected retry")
+	}
+	_, err := NewClient(nil, WithRetry(p)).downloadZip(context.Background(), "ftp://www.pochta.ru/package.zip")
+	require.NotNil(t, err)
+}
+
+func TestRetryPolicy_wait(t *testing.T) {
+	p := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	d := p.wait(1, 0)
+	require.True(t, d >= 500*time.Millisecond && d <= time.Second)
+	require.Equal(t, 5*time.Second, p.wait(1, 5*time.Second))
+	require.Equal(t, 10*time.Second, p.wait(1, time.Hour))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	d := p.backoff(1)
+	require.True(t, d >= 500*time.Millisecond && d <= time.Second)
+
+	d = p.backoff(3)
+	require.True(t, d >= 2*time.Second && d <= 4*time.Second)
+
+	d = p.backoff(100)
+	require.True(t, d >= 5*time.Second && d <= 10*time.Second)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	require.Equal(t, time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+}
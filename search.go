@@ -0,0 +1,289 @@
+package pindxru
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchField Поле записи для поиска и его вес при ранжировании.
+type searchField struct {
+	value  func(PIndx) string
+	weight float64
+}
+
+var searchFields = []searchField{
+	{func(p PIndx) string { return p.City }, 1},
+	{func(p PIndx) string { return p.OpsName }, 1},
+	{func(p PIndx) string { return p.SubCity }, 0.9},
+	{func(p PIndx) string { return p.Area }, 0.7},
+	{func(p PIndx) string { return p.Region }, 0.5},
+	{func(p PIndx) string { return p.Autonomy }, 0.5},
+}
+
+// searchAbbreviations Сокращения, которые заменяются полными словами. Пустая строка - слово
+// не участвует в поиске, например, тип населенного пункта, которого нет в полях справочника.
+var searchAbbreviations = map[string]string{
+	"г": "", "гор": "", "город": "",
+	"п": "", "пос": "", "поселок": "", "пгт": "", "рп": "",
+	"с": "", "село": "",
+	"д": "", "дер": "", "деревня": "",
+	"ст": "", "станица": "", "х": "", "хутор": "",
+	"р-н": "район", "рн": "район",
+	"обл":  "область",
+	"респ": "республика",
+	"ао":   "автономный округ",
+}
+
+// SearchResult Найденная запись и ее оценка от 0 до 1.
+type SearchResult struct {
+	PIndx
+	Score float64
+}
+
+// SearchOption Настройка поиска.
+type SearchOption func(*searchOptions)
+
+type searchOptions struct {
+	regionCode int
+	limit      int
+}
+
+// SearchRegion Ограничивает поиск регионом с кодом code.
+func SearchRegion(code int) SearchOption {
+	return func(o *searchOptions) {
+		o.regionCode = code
+	}
+}
+
+// SearchLimit Задает максимальное количество результатов. По умолчанию 20, 0 - без ограничения.
+func SearchLimit(limit int) SearchOption {
+	return func(o *searchOptions) {
+		o.limit = limit
+	}
+}
+
+// searchPosting Запись, в поле которой есть слово.
+type searchPosting struct {
+	item   int
+	weight float64
+}
+
+// searchIndex Обратный индекс слов полей записей.
+type searchIndex struct {
+	postings map[string][]searchPosting
+	// Слова в порядке возрастания для поиска по началу слова
+	words []string
+}
+
+func newSearchIndex(items []PIndx) (idx *searchIndex) {
+	idx = &searchIndex{postings: map[string][]searchPosting{}}
+	for i, p := range items {
+		best := map[string]float64{}
+		for _, f := range searchFields {
+			for _, word := range normalizeSearchText(f.value(p)) {
+				if f.weight > best[word] {
+					best[word] = f.weight
+				}
+			}
+		}
+
+		for word, weight := range best {
+			idx.postings[word] = append(idx.postings[word], searchPosting{item: i, weight: weight})
+		}
+	}
+
+	for word := range idx.postings {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	return
+}
+
+// Search Ищет объекты почтовой связи по произвольному тексту, например, «пос. Октябрьский, Рязанская обл».
+//
+// Поиск ведется по наименованию объекта, населенному пункту, подчиненному населенному пункту,
+// району и региону. Регистр, буквы ё/е, знаки препинания и сокращения (г., пос., с., д., р-н, обл.)
+// не учитываются, допускаются опечатки и неполные слова. Каждое слово запроса должно найтись
+// хотя бы в одном поле записи.
+//
+// Результаты упорядочены по убыванию оценки.
+func (d *Directory) Search(query string, opts ...SearchOption) (results []SearchResult) {
+	o := searchOptions{limit: 20}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := d.load()
+	s.searchOnce.Do(func() {
+		s.search = newSearchIndex(s.items)
+	})
+
+	results = []SearchResult{}
+	words := normalizeSearchText(query)
+	if len(words) == 0 {
+		return
+	}
+
+	var scores map[int]float64
+	for n, word := range words {
+		matches := map[int]float64{}
+		for candidate, quality := range s.search.match(word) {
+			for _, posting := range s.search.postings[candidate] {
+				if score := quality * posting.weight; score > matches[posting.item] {
+					matches[posting.item] = score
+				}
+			}
+		}
+
+		if n == 0 {
+			scores = matches
+			continue
+		}
+
+		for item, score := range scores {
+			if m, ok := matches[item]; ok {
+				scores[item] = score + m
+			} else {
+				delete(scores, item)
+			}
+		}
+	}
+
+	for item, score := range scores {
+		p := s.items[item]
+		if o.regionCode > 0 && p.RegionCode != o.regionCode {
+			continue
+		}
+		results = append(results, SearchResult{PIndx: p, Score: score / float64(len(words))})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Index < results[j].Index
+	})
+
+	if o.limit > 0 && len(results) > o.limit {
+		results = results[:o.limit]
+	}
+	return
+}
+
+// match Возвращает слова индекса, похожие на word, и качество совпадения:
+// 1 - слово совпадает, 0.8 - word является началом слова, меньше - слово с опечатками.
+func (idx *searchIndex) match(word string) (matches map[string]float64) {
+	matches = map[string]float64{}
+	if _, ok := idx.postings[word]; ok {
+		matches[word] = 1
+	}
+
+	size := len([]rune(word))
+	if size >= 3 {
+		from := sort.SearchStrings(idx.words, word)
+		for _, w := range idx.words[from:] {
+			if !strings.HasPrefix(w, word) {
+				break
+			}
+			if w != word {
+				matches[w] = 0.8
+			}
+		}
+	}
+
+	maxDistance := 0
+	switch {
+	case size >= 8:
+		maxDistance = 2
+	case size >= 4:
+		maxDistance = 1
+	}
+	if maxDistance == 0 {
+		return
+	}
+
+	for _, w := range idx.words {
+		if _, ok := matches[w]; ok {
+			continue
+		}
+
+		if dist := editDistance(word, w, maxDistance); dist <= maxDistance {
+			matches[w] = 0.6 - 0.2*float64(dist-1)
+		}
+	}
+	return
+}
+
+// normalizeSearchText Разбивает текст на слова: приводит к нижнему регистру, заменяет ё на е,
+// удаляет знаки препинания и раскрывает сокращения.
+func normalizeSearchText(text string) (words []string) {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+
+	for _, field := range fields {
+		field = strings.Trim(field, "-")
+		if full, ok := searchAbbreviations[field]; ok {
+			words = append(words, strings.Fields(full)...)
+			continue
+		}
+
+		// составные названия, например, Ханты-Мансийск, ищутся и по частям
+		words = append(words, strings.FieldsFunc(field, func(r rune) bool {
+			return r == '-'
+		})...)
+	}
+	return
+}
+
+// editDistance Расстояние Дамерау-Левенштейна между a и b. Если расстояние больше limit,
+// то возвращает limit+1.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+			rowMin = minInt(rowMin, cur[j])
+		}
+
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	if prev[len(rb)] > limit {
+		return limit + 1
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
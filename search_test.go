@@ -0,0 +1,67 @@
+package pindxru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory_Search(t *testing.T) {
+	d := NewDirectory(append(testReadZip(t, testPIndxZip, ReadPIndxZip),
+		PIndx{Index: "391220", OpsName: "ОКТЯБРЬСКИЙ", City: "ОКТЯБРЬСКИЙ", Area: "МИХАЙЛОВСКИЙ РАЙОН",
+			Region: "РЯЗАНСКАЯ ОБЛАСТЬ", RegionCode: 62},
+		PIndx{Index: "452600", OpsName: "ОКТЯБРЬСКИЙ", City: "ОКТЯБРЬСКИЙ",
+			Region: "БАШКОРТОСТАН РЕСПУБЛИКА", RegionCode: 2},
+		PIndx{Index: "140060", OpsName: "ЛЮБЕРЦЫ 60", City: "ЛЮБЕРЦЫ", SubCity: "ОКТЯБРЬСКИЙ",
+			Region: "МОСКОВСКАЯ ОБЛАСТЬ", RegionCode: 50},
+		PIndx{Index: "391221", OpsName: "ЁЛКИ", City: "ЁЛКИ", Area: "МИХАЙЛОВСКИЙ РАЙОН",
+			Region: "РЯЗАНСКАЯ ОБЛАСТЬ", RegionCode: 62},
+	))
+
+	results := d.Search("пос. Октябрьский, Рязанская обл")
+	require.Len(t, results, 1)
+	require.Equal(t, "391220", results[0].Index)
+	require.InDelta(t, (1+0.5+0.5)/3, results[0].Score, 1e-9)
+
+	// населенный пункт выше подчиненного населенного пункта
+	results = d.Search("октябрьский")
+	require.Equal(t, []string{"391220", "452600", "140060"}, testSearchIndexes(results))
+	require.Equal(t, []string{"140060"}, testSearchIndexes(d.Search("Октябрьский", SearchRegion(50))))
+	require.Len(t, d.Search("октябрьский", SearchLimit(2)), 2)
+
+	// опечатки и неполные слова
+	require.Equal(t, []string{"391220"}, testSearchIndexes(d.Search("Октябрский рязанская")))
+	require.Equal(t, []string{"391220"}, testSearchIndexes(d.Search("Октяьбрский, рязан")))
+	require.Equal(t, []string{"391220", "391221"}, testSearchIndexes(d.Search("Михайловский р-н")))
+
+	// ё и е, регистр, составные названия
+	require.Equal(t, []string{"391221"}, testSearchIndexes(d.Search("д. елки")))
+	require.Equal(t, []string{"628001"}, testSearchIndexes(d.Search("г.Ханты-Мансийск")))
+	require.Equal(t, []string{"628001"}, testSearchIndexes(d.Search("мансийск")))
+
+	require.Equal(t, "664001", d.Search("Иркутск 1")[0].Index)
+	require.Empty(t, d.Search("Владивосток"))
+	require.Empty(t, d.Search(" г. , "))
+	require.Empty(t, (&Directory{}).Search("Иркутск"))
+}
+
+func testSearchIndexes(results []SearchResult) (list []string) {
+	list = []string{}
+	for _, r := range results {
+		list = append(list, r.Index)
+	}
+	return
+}
+
+func Test_normalizeSearchText(t *testing.T) {
+	require.Equal(t, []string{"октябрьский", "рязанская", "область"}, normalizeSearchText("пос. Октябрьский, Рязанская обл."))
+	require.Equal(t, []string{"михайловский", "район", "елки"}, normalizeSearchText("Михайловский р-н, д.Ёлки"))
+	require.Equal(t, []string{"ханты", "мансийск"}, normalizeSearchText("г. Ханты-Мансийск"))
+}
+
+func Test_editDistance(t *testing.T) {
+	require.Equal(t, 0, editDistance("иркутск", "иркутск", 2))
+	require.Equal(t, 1, editDistance("иркуткс", "иркутск", 2))
+	require.Equal(t, 1, editDistance("ирутск", "иркутск", 2))
+	require.Equal(t, 3, editDistance("москва", "иркутск", 2))
+}
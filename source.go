@@ -0,0 +1,303 @@
+package pindxru
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReferenceSource Источник списка обновлений справочника.
+type ReferenceSource interface {
+	ReferenceRows(ctx context.Context) (ReferenceRows, error)
+}
+
+// fileClient Загружает архивы по ссылкам file://, которые возвращают ManifestSource и DirSource.
+var fileClient = &http.Client{Transport: http.NewFileTransport(http.Dir("/"))}
+
+// localSource Источник, строки которого ссылаются на локальные файлы.
+type localSource interface {
+	ReferenceSource
+	localFiles()
+}
+
+// localFiles Загружает ли клиент архивы по ссылкам file://. Разрешено только для источников
+// ManifestSource и DirSource, иначе ссылка со страницы или зеркала позволила бы прочитать
+// любой локальный файл.
+func (c Client) localFiles() bool {
+	_, ok := c.source.(localSource)
+	return ok
+}
+
+// PageSource Список обновлений со страницы web-справочника.
+type PageSource struct {
+	c *Client
+}
+
+// NewPageSource Возвращает источник, который загружает страницу web-справочника клиентом c.
+func NewPageSource(c *Client) *PageSource {
+	return &PageSource{c: c}
+}
+
+// ReferenceRows Загружает и разбирает страницу web-справочника.
+func (s *PageSource) ReferenceRows(ctx context.Context) (referenceRows ReferenceRows, err error) {
+	var b []byte
+	if b, err = s.c.loadPage(ctx); err != nil {
+		return
+	}
+
+	referenceRows, err = parseReferenceRows(b, s.c.baseURL+listUpdatesPath)
+	return
+}
+
+// ManifestSource Список обновлений из файла JSON или YAML. Используется в сетях без доступа
+// к web-справочнику, когда архивы копируются вручную.
+//
+// Пример манифеста в формате YAML:
+//
+//	rows:
+//	  - date: 2021-03-10
+//	    number: "04"
+//	    update: {url: NPIndx04.zip, records: 3}
+//	    full: {url: PIndx04.zip, records: 5, sha256: 9f86d08...}
+//
+// Относительные ссылки считаются путями к файлам относительно каталога манифеста.
+type ManifestSource struct {
+	path string
+}
+
+// NewManifestSource Возвращает источник, который читает манифест path.
+// Формат определяется по расширению: .json, .yaml или .yml.
+func NewManifestSource(path string) *ManifestSource {
+	return &ManifestSource{path: path}
+}
+
+type manifest struct {
+	Rows []manifestRow `json:"rows" yaml:"rows"`
+}
+
+type manifestRow struct {
+	Date   string       `json:"date" yaml:"date"`
+	Number string       `json:"number" yaml:"number"`
+	Update manifestFile `json:"update" yaml:"update"`
+	Full   manifestFile `json:"full" yaml:"full"`
+}
+
+type manifestFile struct {
+	Url     string `json:"url" yaml:"url"`
+	Records int    `json:"records" yaml:"records"`
+	SHA256  string `json:"sha256" yaml:"sha256"`
+}
+
+func (s *ManifestSource) localFiles() {}
+
+// ReferenceRows Читает строки из манифеста.
+func (s *ManifestSource) ReferenceRows(ctx context.Context) (referenceRows ReferenceRows, err error) {
+	var b []byte
+	if b, err = os.ReadFile(s.path); err != nil {
+		return
+	}
+
+	m := manifest{}
+	switch strings.ToLower(filepath.Ext(s.path)) {
+	case ".json":
+		err = json.Unmarshal(b, &m)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &m)
+	default:
+		err = fmt.Errorf("неизвестный формат манифеста %s", s.path)
+	}
+	if err != nil {
+		return
+	}
+
+	dir := filepath.Dir(s.path)
+	referenceRows = ReferenceRows{}
+	for i, mr := range m.Rows {
+		r := ReferenceRow{Number: mr.Number}
+		if r.Date, err = time.Parse("2006-01-02", mr.Date); err != nil {
+			return nil, &RowError{Row: i, Field: "date", Err: err}
+		}
+
+		if r.Update, err = mr.Update.referenceFile(dir); err != nil {
+			return nil, &RowError{Row: i, Field: "update", Err: err}
+		}
+
+		if r.Full, err = mr.Full.referenceFile(dir); err != nil {
+			return nil, &RowError{Row: i, Field: "full", Err: err}
+		}
+		referenceRows = append(referenceRows, r)
+	}
+	return
+}
+
+func (f manifestFile) referenceFile(dir string) (rf ReferenceFile, err error) {
+	rf.Records = f.Records
+	rf.SHA256 = strings.ToLower(f.SHA256)
+	if f.Url == "" {
+		return
+	}
+
+	var u *url.URL
+	if u, err = url.Parse(f.Url); err != nil {
+		return
+	}
+
+	if u.IsAbs() {
+		rf.Url = f.Url
+		return
+	}
+
+	path := filepath.FromSlash(f.Url)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	rf.Url, err = fileURL(path)
+	return
+}
+
+// DirSource Список обновлений по архивам в каталоге. Архивы должны называться так же,
+// как в web-справочнике: `PIndx<номер>.zip` и `NPIndx<номер>.zip`.
+//
+// Дата обновления берется из даты dbf-файла в архиве (если она не указана, то из даты
+// изменения архива), а количество записей - из заголовка dbf-файла.
+type DirSource struct {
+	dir string
+}
+
+// NewDirSource Возвращает источник, который просматривает каталог dir.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+func (s *DirSource) localFiles() {}
+
+var archiveNameRe = regexp.MustCompile(`(?i)^(N?PIndx)(\d+)\.zip$`)
+
+// ReferenceRows Возвращает строки по архивам в каталоге, упорядоченные по дате.
+func (s *DirSource) ReferenceRows(ctx context.Context) (referenceRows ReferenceRows, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(s.dir); err != nil {
+		return
+	}
+
+	rows := map[string]*ReferenceRow{}
+	for _, e := range entries {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		m := archiveNameRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+
+		filename := filepath.Join(s.dir, e.Name())
+		var (
+			date time.Time
+			f    ReferenceFile
+		)
+		if date, f.Records, err = archiveInfo(filename); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		if f.Url, err = fileURL(filename); err != nil {
+			return
+		}
+
+		r, ok := rows[m[2]]
+		if !ok {
+			r = &ReferenceRow{Number: m[2]}
+			rows[m[2]] = r
+		}
+
+		if date.After(r.Date) {
+			r.Date = date
+		}
+
+		if strings.EqualFold(m[1], "NPIndx") {
+			r.Update = f
+		} else {
+			r.Full = f
+		}
+	}
+
+	referenceRows = ReferenceRows{}
+	for _, r := range rows {
+		referenceRows = append(referenceRows, *r)
+	}
+
+	referenceRows.Sort()
+	return
+}
+
+// archiveInfo Возвращает дату и количество записей dbf-файла в архиве.
+func archiveInfo(filename string) (date time.Time, records int, err error) {
+	var f *os.File
+	if f, err = os.Open(filename); err != nil {
+		return
+	}
+	defer func() {
+		if derr := f.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	var fi os.FileInfo
+	if fi, err = f.Stat(); err != nil {
+		return
+	}
+
+	var zf *zip.File
+	if zf, err = findDbf(f, fi.Size()); err != nil {
+		return
+	}
+
+	modified := zf.Modified
+	// в zip-файле дата не указана
+	if modified.Year() < 1990 {
+		modified = fi.ModTime()
+	}
+	date = time.Date(modified.Year(), modified.Month(), modified.Day(), 0, 0, 0, 0, time.UTC)
+
+	var rc io.ReadCloser
+	if rc, err = zf.Open(); err != nil {
+		return
+	}
+	defer func() {
+		if derr := rc.Close(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+
+	var dbf *dbfReader
+	if dbf, err = newDbfReader(rc, fileEncoding); err != nil {
+		return
+	}
+	records = dbf.NumberOfRecords()
+	return
+}
+
+// fileURL Возвращает ссылку file:// на файл.
+func fileURL(path string) (u string, err error) {
+	if path, err = filepath.Abs(path); err != nil {
+		return
+	}
+
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u = (&url.URL{Scheme: "file", Path: path}).String()
+	return
+}
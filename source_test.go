@@ -0,0 +1,143 @@
+package pindxru
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NovikovRoman/pindxru/internal/dbftest"
+	"github.com/stretchr/testify/require"
+)
+
+func testCopyFile(t *testing.T, src, dst string) {
+	b, err := os.ReadFile(src)
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(dst, b, 0666))
+}
+
+func TestPageSource(t *testing.T) {
+	ts := newTestServer(t, testOpsPage)
+	rows, err := NewPageSource(NewClient(nil, WithBaseURL(ts.URL))).ReferenceRows(context.Background())
+	require.Nil(t, err)
+	require.Len(t, rows, 3)
+}
+
+func TestManifestSource(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "archives"), 0777))
+	testCopyFile(t, filepath.Join(testdata, testPIndxZip), filepath.Join(dir, "archives", "PIndx04.zip"))
+	testCopyFile(t, filepath.Join(testdata, testNPIndxZip), filepath.Join(dir, "archives", "NPIndx04.zip"))
+
+	manifests := map[string]string{
+		"manifest.json": `{"rows": [
+  {"date": "2021-03-10", "number": "04",
+   "update": {"url": "archives/NPIndx04.zip", "records": 3},
+   "full": {"url": "archives/PIndx04.zip", "records": 5}}
+]}`,
+		"manifest.yaml": `rows:
+  - date: "2021-03-10"
+    number: "04"
+    update: {url: archives/NPIndx04.zip, records: 3}
+    full: {url: archives/PIndx04.zip, records: 5}
+`,
+	}
+
+	for name, content := range manifests {
+		filename := filepath.Join(dir, name)
+		require.Nil(t, os.WriteFile(filename, []byte(content), 0666))
+
+		c := NewClient(nil, WithSource(NewManifestSource(filename)))
+		rows, err := c.GetReferenceRows()
+		require.Nil(t, err, name)
+		require.Len(t, rows, 1, name)
+		require.Equal(t, time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC), rows[0].Date, name)
+		require.Equal(t, "04", rows[0].Number, name)
+		require.Regexp(t, `^file:///.+/archives/PIndx04\.zip$`, rows[0].Full.Url, name)
+		require.Equal(t, 3, rows[0].Update.Records, name)
+
+		// архивы загружаются с диска
+		indexes, _, err := c.Indexes(rows, nil)
+		require.Nil(t, err, name)
+		require.Len(t, indexes, len(testPIndxRows), name)
+
+		packages, err := rows.GetUpdatePackages(nil)
+		require.Nil(t, err, name)
+		_, err = c.GetPackageIndexes(&packages[0])
+		require.Nil(t, err, name)
+		require.Len(t, packages[0].Indexes, len(testNPIndxRows), name)
+	}
+
+	// абсолютные ссылки не изменяются
+	filename := filepath.Join(dir, "mirror.json")
+	require.Nil(t, os.WriteFile(filename, []byte(`{"rows": [{"date": "2021-03-10", "number": "04",
+  "update": {"url": "https://mirror.local/NPIndx04.zip"}, "full": {"url": "https://mirror.local/PIndx04.zip"}}]}`), 0666))
+	rows, err := NewManifestSource(filename).ReferenceRows(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "https://mirror.local/PIndx04.zip", rows[0].Full.Url)
+
+	filename = filepath.Join(dir, "invalid.yml")
+	require.Nil(t, os.WriteFile(filename, []byte("rows:\n  - date: 2021-03-10\n  - date: 10.03.2021\n"), 0666))
+	_, err = NewManifestSource(filename).ReferenceRows(context.Background())
+	var rowErr *RowError
+	require.ErrorAs(t, err, &rowErr)
+	require.Equal(t, 1, rowErr.Row)
+	require.Equal(t, "date", rowErr.Field)
+
+	_, err = NewManifestSource(filepath.Join(dir, "manifest.txt")).ReferenceRows(context.Background())
+	require.NotNil(t, err)
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	testCopyFile(t, filepath.Join(testdata, testPIndxZip), filepath.Join(dir, "PIndx05.zip"))
+	testCopyFile(t, filepath.Join(testdata, testNPIndxZip), filepath.Join(dir, "NPIndx05.zip"))
+	testCopyFile(t, filepath.Join(testdata, testPIndxZip), filepath.Join(dir, "PIndx04.zip"))
+	testCopyFile(t, filepath.Join(testdata, testNPIndxZip), filepath.Join(dir, "NPIndx04.zip"))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("-"), 0666))
+
+	d4 := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	d5 := time.Date(2021, 3, 19, 12, 0, 0, 0, time.UTC)
+	for name, d := range map[string]time.Time{"PIndx04.zip": d4, "NPIndx04.zip": d4, "PIndx05.zip": d5, "NPIndx05.zip": d5} {
+		require.Nil(t, os.Chtimes(filepath.Join(dir, name), d, d))
+	}
+
+	c := NewClient(nil, WithSource(NewDirSource(dir)))
+	rows, err := c.GetReferenceRows()
+	require.Nil(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "04", rows[0].Number)
+	require.Equal(t, "05", rows[1].Number)
+	require.Equal(t, time.Date(d5.Local().Year(), d5.Local().Month(), d5.Local().Day(), 0, 0, 0, 0, time.UTC), rows[1].Date)
+	require.Equal(t, len(testPIndxRows), rows[1].Full.Records)
+	require.Equal(t, len(testNPIndxRows), rows[1].Update.Records)
+
+	indexes, _, err := c.Indexes(rows, nil)
+	require.Nil(t, err)
+	require.Len(t, indexes, len(testPIndxRows))
+
+	// архив без dbf-файла
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "PIndx06.zip"), dbftest.MakeZip("readme.txt", []byte("-")), 0666))
+	_, err = c.GetReferenceRows()
+	require.ErrorIs(t, err, ErrNoDbfInArchive)
+}
+
+func TestClient_localFiles(t *testing.T) {
+	dir := t.TempDir()
+	testCopyFile(t, filepath.Join(testdata, testNPIndxZip), filepath.Join(dir, "NPIndx04.zip"))
+
+	rows, err := NewDirSource(dir).ReferenceRows(context.Background())
+	require.Nil(t, err)
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+
+	// ссылки file:// загружаются только клиентом с локальным источником
+	filename := filepath.Join(dir, testZipFile)
+	require.NotNil(t, NewClient(nil).PackageZip(packages[0], filename, 0666))
+	_, err = os.Stat(filename)
+	require.True(t, os.IsNotExist(err))
+
+	require.Nil(t, NewClient(nil, WithSource(NewDirSource(dir))).PackageZip(packages[0], filename, 0666))
+	testCheckFile(t, filename)
+}
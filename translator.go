@@ -0,0 +1,136 @@
+package pindxru
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TranslationVia Способ перехода от одного индекса к другому.
+type TranslationVia string
+
+const (
+	// ViaOldIndex индекс до ввода действующей системы индексации (PIndx.OldIndex).
+	ViaOldIndex TranslationVia = "old_index"
+	// ViaRenumbered объект перешел на новый индекс в пакете изменений (NPIndx.NewIndex).
+	ViaRenumbered TranslationVia = "renumbered"
+)
+
+// TranslationStep Шаг перехода от индекса From к индексу To.
+type TranslationStep struct {
+	From string
+	To   string
+	Via  TranslationVia
+	// Дата пакета изменений для ViaRenumbered
+	Date time.Time
+}
+
+// Translation Результат перевода индекса в действующий.
+type Translation struct {
+	// Действующий индекс
+	Index string
+	// Шаги от исходного индекса к действующему, пустой, если исходный индекс действующий
+	Path []TranslationStep
+}
+
+// Translator Переводит старые и измененные индексы в действующие, например, для обновления
+// адресов, сохраненных до изменения индексов. Безопасен для одновременного использования.
+type Translator struct {
+	current    map[string]bool
+	old        map[string][]string
+	renumbered map[string]TranslationStep
+	closed     map[string]time.Time
+}
+
+// NewTranslator Создает переводчик по действующему справочнику current и примененным к нему
+// пакетам изменений pkgs (см. Apply). У пакетов должны быть загружены индексы, иначе
+// возвращается ErrPackageNotLoaded.
+func NewTranslator(current []PIndx, pkgs ...Package) (t *Translator, err error) {
+	if err = checkLoaded(pkgs); err != nil {
+		return
+	}
+
+	t = &Translator{
+		current:    make(map[string]bool, len(current)),
+		old:        map[string][]string{},
+		renumbered: map[string]TranslationStep{},
+		closed:     map[string]time.Time{},
+	}
+
+	for _, p := range current {
+		t.current[p.Index] = true
+		if p.OldIndex != "" && p.OldIndex != p.Index {
+			t.old[p.OldIndex] = append(t.old[p.OldIndex], p.Index)
+		}
+	}
+
+	for _, indexes := range t.old {
+		sort.Strings(indexes)
+	}
+
+	sorted := append([]Package{}, pkgs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	// более поздние пакеты заменяют переходы из более ранних
+	for _, pack := range sorted {
+		for _, np := range pack.Indexes {
+			switch {
+			case np.NewIndex == "":
+				t.closed[np.Index] = pack.Date
+				delete(t.renumbered, np.Index)
+			case np.NewIndex != np.Index:
+				t.renumbered[np.Index] = TranslationStep{From: np.Index, To: np.NewIndex, Via: ViaRenumbered, Date: pack.Date}
+				delete(t.closed, np.Index)
+			}
+		}
+	}
+	return
+}
+
+// Translate Возвращает действующий индекс для index и шаги, по которым он найден.
+//
+// Если объект закрыт, то возвращается ErrIndexClosed, если индекс не найден - ErrIndexNotFound,
+// если старому индексу соответствует несколько действующих - ErrAmbiguousIndex.
+// При ошибке Path содержит пройденные шаги.
+func (t *Translator) Translate(index string) (tr Translation, err error) {
+	index = strings.TrimSpace(index)
+	visited := map[string]bool{}
+	for {
+		if t.current[index] {
+			tr.Index = index
+			return
+		}
+
+		if visited[index] {
+			err = fmt.Errorf("%w: цикл переходов на индексе %s", ErrIndexNotFound, index)
+			return
+		}
+		visited[index] = true
+
+		if step, ok := t.renumbered[index]; ok {
+			tr.Path = append(tr.Path, step)
+			index = step.To
+			continue
+		}
+
+		if indexes := t.old[index]; len(indexes) == 1 {
+			tr.Path = append(tr.Path, TranslationStep{From: index, To: indexes[0], Via: ViaOldIndex})
+			index = indexes[0]
+			continue
+		} else if len(indexes) > 1 {
+			err = fmt.Errorf("%w: %s -> %s", ErrAmbiguousIndex, index, strings.Join(indexes, ", "))
+			return
+		}
+
+		if date, ok := t.closed[index]; ok {
+			err = fmt.Errorf("%w: %s закрыт %s", ErrIndexClosed, index, date.Format("02.01.2006"))
+			return
+		}
+
+		err = fmt.Errorf("%w: %s", ErrIndexNotFound, index)
+		return
+	}
+}
@@ -0,0 +1,72 @@
+package pindxru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	snapshot := append(testReadZip(t, testPIndxZip, ReadPIndxZip),
+		PIndx{Index: "101000", OldIndex: "K-101"},
+		PIndx{Index: "101001", OldIndex: "K-100"},
+		PIndx{Index: "101002", OldIndex: "K-100"},
+	)
+
+	d1 := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC)
+	pkgs := []Package{
+		{Date: d2, Indexes: []NPIndx{
+			{Index: "664521", NewIndex: "664530", OpsName: "МАРКОВА", OpsSub: "664000"},
+			{Index: "628001"},
+		}},
+		{Date: d1, Indexes: testReadZip(t, testNPIndxZip, ReadNPIndxZip)},
+	}
+
+	current, _, err := Apply(snapshot, pkgs...)
+	require.Nil(t, err)
+	tr, err := NewTranslator(current, pkgs...)
+	require.Nil(t, err)
+
+	res, err := tr.Translate("664001")
+	require.Nil(t, err)
+	require.Equal(t, Translation{Index: "664001"}, res)
+
+	res, err = tr.Translate(" 664520")
+	require.Nil(t, err)
+	require.Equal(t, "664530", res.Index)
+	require.Equal(t, []TranslationStep{
+		{From: "664520", To: "664521", Via: ViaRenumbered, Date: d1},
+		{From: "664521", To: "664530", Via: ViaRenumbered, Date: d2},
+	}, res.Path)
+
+	res, err = tr.Translate("K-101")
+	require.Nil(t, err)
+	require.Equal(t, "101000", res.Index)
+	require.Equal(t, []TranslationStep{{From: "K-101", To: "101000", Via: ViaOldIndex}}, res.Path)
+
+	_, err = tr.Translate("K-100")
+	require.ErrorIs(t, err, ErrAmbiguousIndex)
+
+	_, err = tr.Translate("628001")
+	require.ErrorIs(t, err, ErrIndexClosed)
+
+	_, err = tr.Translate("999999")
+	require.ErrorIs(t, err, ErrIndexNotFound)
+
+	// цикл переходов без действующего индекса
+	tr, err = NewTranslator(nil, Package{Indexes: []NPIndx{
+		{Index: "101000", NewIndex: "101001"},
+		{Index: "101001", NewIndex: "101000"},
+	}})
+	require.Nil(t, err)
+	res, err = tr.Translate("101000")
+	require.ErrorIs(t, err, ErrIndexNotFound)
+	require.Len(t, res.Path, 2)
+
+	// индексы пакета не загружены
+	tr, err = NewTranslator(current, Package{Url: "NPIndx01.zip", NumberRecords: 3, Indexes: []NPIndx{}})
+	require.ErrorIs(t, err, ErrPackageNotLoaded)
+	require.Nil(t, tr)
+}
@@ -1,58 +1,147 @@
 package pindxru
 
 import (
-	"archive/zip"
+	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
-
-	"github.com/NovikovRoman/godbf"
+	"os"
+	"strings"
 )
 
-func dbfToPIndx(table *godbf.DbfTable) ([]PIndx, error) {
-	postIndexes := make([]PIndx, table.NumberOfRecords())
+func dbfEachPIndx(ctx context.Context, dbf *dbfReader, fn func(PIndx) error) error {
+	for row := 0; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for row := 0; row < table.NumberOfRecords(); row++ {
-		p, err := createPIndx(table.GetRowAsSlice(row))
+		data, err := dbf.Next()
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
-			return nil, fmt.Errorf("row %d: %s", row, err)
+			return newRowError(row, err)
 		}
-		postIndexes[row] = p
-	}
 
-	return postIndexes, nil
+		p, err := createPIndx(data)
+		if err != nil {
+			return newRowError(row, err)
+		}
+
+		if err = fn(p); err != nil {
+			return err
+		}
+	}
 }
 
-func dbfToNPIndx(table *godbf.DbfTable) ([]NPIndx, error) {
-	postIndexes := make([]NPIndx, table.NumberOfRecords())
+func dbfEachNPIndx(ctx context.Context, dbf *dbfReader, fn func(NPIndx) error) error {
+	for row := 0; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := dbf.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newRowError(row, err)
+		}
 
-	for row := 0; row < table.NumberOfRecords(); row++ {
-		p, err := createNPIndx(table.GetRowAsSlice(row))
+		p, err := createNPIndx(data)
 		if err != nil {
-			return nil, fmt.Errorf("row %d: %s", row, err)
+			return newRowError(row, err)
+		}
+
+		if err = fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// saveTempFile Сохраняет тело ответа во временный файл.
+func saveTempFile(resp *http.Response) (f *os.File, err error) {
+	defer func() {
+		if derr := resp.Body.Close(); derr != nil && err == nil {
+			err = derr
 		}
-		postIndexes[row] = p
+
+		if err != nil && f != nil {
+			removeTempFile(f)
+			f = nil
+		}
+	}()
+
+	if f, err = os.CreateTemp("", "pindxru-*.zip"); err != nil {
+		return
 	}
 
-	return postIndexes, nil
+	_, err = io.Copy(f, resp.Body)
+	return
 }
 
-func readZipFile(zf *zip.File) (body []byte, error error) {
-	f, err := zf.Open()
-	if err != nil {
+// removeTempFile Закрывает и удаляет временный файл.
+func removeTempFile(f *os.File) {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// writeFile Записывает данные из r в файл filename.
+func writeFile(filename string, r io.Reader, perm os.FileMode) (err error) {
+	var f *os.File
+	if f, err = os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm); err != nil {
 		return
 	}
 
 	defer func() {
-		if derr := f.Close(); derr != nil {
+		if derr := f.Close(); derr != nil && err == nil {
 			err = derr
 		}
 	}()
 
-	body, err = io.ReadAll(f)
+	_, err = io.Copy(f, r)
+	return
+}
+
+// bodySnippetSize Размер начала тела ответа, сохраняемого в HTTPError.
+const bodySnippetSize = 512
+
+// checkResponse Проверяет код и тип содержимого ответа. При ошибке тело ответа закрывается.
+func checkResponse(resp *http.Response, validContentType func(string) bool) (err error) {
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		ct := resp.Header.Get("Content-Type")
+		if ct == "" || validContentType(ct) {
+			return
+		}
+		err = fmt.Errorf("%w: %s (%s)", ErrContentType, ct, resp.Request.URL)
+		_ = resp.Body.Close()
+		return
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, bodySnippetSize))
+	_ = resp.Body.Close()
+
+	err = &HTTPError{
+		StatusCode:  resp.StatusCode,
+		URL:         resp.Request.URL.String(),
+		BodySnippet: string(snippet),
+	}
 	return
 }
 
+func isHTMLContentType(ct string) bool {
+	mediaType, _, _ := mime.ParseMediaType(ct)
+	return mediaType == "text/html"
+}
+
+// isArchiveContentType Архив отдается с разными типами, поэтому отсекается только текстовое содержимое
+// (страницы ошибок, капча).
+func isArchiveContentType(ct string) bool {
+	mediaType, _, _ := mime.ParseMediaType(ct)
+	return !strings.HasPrefix(mediaType, "text/")
+}
+
 func getBody(resp *http.Response) (body []byte, err error) {
 	defer func() {
 		if derr := resp.Body.Close(); derr != nil {
@@ -0,0 +1,163 @@
+package pindxru
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationReason Причина, по которой почтовый индекс не прошел проверку.
+type ValidationReason string
+
+const (
+	// ReasonFormat индекс не состоит из 6 цифр.
+	ReasonFormat ValidationReason = "format"
+	// ReasonUnknownPrefix первые 3 цифры индекса не относятся ни к одному региону.
+	ReasonUnknownPrefix ValidationReason = "unknown_prefix"
+	// ReasonNotFound индекса нет в справочнике.
+	ReasonNotFound ValidationReason = "not_found"
+	// ReasonUnknownRegion указанный регион не найден.
+	ReasonUnknownRegion ValidationReason = "unknown_region"
+	// ReasonRegionMismatch указанный регион не совпадает с регионом индекса.
+	ReasonRegionMismatch ValidationReason = "region_mismatch"
+)
+
+// ValidationIssue Причина и описание ошибки проверки.
+type ValidationIssue struct {
+	Reason  ValidationReason
+	Message string
+}
+
+// ValidationResult Результат проверки почтового индекса.
+type ValidationResult struct {
+	// Индекс без пробелов по краям
+	Index string
+	Valid bool
+	// Код региона по первым 3 цифрам индекса
+	RegionCode int
+	// Запись справочника, если указан ValidateDirectory и индекс найден
+	PIndx *PIndx
+	// Ошибки проверки, пустой для корректного индекса
+	Issues []ValidationIssue
+}
+
+// ValidateOption Настройка проверки почтового индекса.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	directory  *Directory
+	region     string
+	regionCode int
+}
+
+// ValidateDirectory Проверяет, что индекс есть в справочнике d.
+func ValidateDirectory(d *Directory) ValidateOption {
+	return func(o *validateOptions) {
+		o.directory = d
+	}
+}
+
+// ValidateRegion Проверяет, что индекс относится к региону с названием region, например,
+// указанному покупателем в адресе.
+func ValidateRegion(region string) ValidateOption {
+	return func(o *validateOptions) {
+		o.region = region
+	}
+}
+
+// ValidateRegionCode Проверяет, что индекс относится к региону с кодом code.
+func ValidateRegionCode(code int) ValidateOption {
+	return func(o *validateOptions) {
+		o.regionCode = code
+	}
+}
+
+// regionParts Автономные округа, которые входят в состав области.
+var regionParts = map[int][]int{
+	29: {83},     // Архангельская область: Ненецкий АО
+	72: {86, 89}, // Тюменская область: Ханты-Мансийский АО, Ямало-Ненецкий АО
+}
+
+// Validate Проверяет почтовый индекс: формат, регион по первым 3 цифрам и, если указаны
+// опции, наличие в справочнике и соответствие региону.
+func Validate(index string, opts ...ValidateOption) (result ValidationResult) {
+	o := validateOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	result.Index = strings.TrimSpace(index)
+	addIssue := func(reason ValidationReason, format string, a ...interface{}) {
+		result.Issues = append(result.Issues, ValidationIssue{Reason: reason, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if !isIndexFormat(result.Index) {
+		addIssue(ReasonFormat, "индекс %q должен состоять из 6 цифр", result.Index)
+		return
+	}
+
+	var ok bool
+	if result.RegionCode, ok = postalCodes[result.Index[:3]]; !ok {
+		addIssue(ReasonUnknownPrefix, "индексы %s... не относятся ни к одному региону", result.Index[:3])
+	}
+
+	if o.directory != nil {
+		if p, ok := o.directory.Get(result.Index); ok {
+			result.PIndx = &p
+		} else {
+			addIssue(ReasonNotFound, "индекса %s нет в справочнике", result.Index)
+		}
+	}
+
+	expected := o.regionCode
+	if o.region != "" {
+		if expected, _ = Regions.GetCode(strings.TrimSpace(o.region)); expected == 0 {
+			addIssue(ReasonUnknownRegion, "регион %q не найден", o.region)
+		}
+	}
+
+	if expected > 0 && result.RegionCode > 0 && !inRegion(result.RegionCode, expected) {
+		addIssue(ReasonRegionMismatch, "индекс %s относится к региону «%s», а не «%s»",
+			result.Index, Regions.GetName(result.RegionCode), Regions.GetName(expected))
+	}
+
+	result.Valid = len(result.Issues) == 0
+	return
+}
+
+// Has Есть ли среди ошибок проверки ошибка reason.
+func (r ValidationResult) Has(reason ValidationReason) bool {
+	for _, issue := range r.Issues {
+		if issue.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// isIndexFormat Состоит ли индекс из 6 цифр.
+func isIndexFormat(index string) bool {
+	if len(index) != 6 {
+		return false
+	}
+
+	for _, r := range index {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// inRegion Относится ли регион code к региону region, в том числе как автономный округ в составе области.
+func inRegion(code, region int) bool {
+	if code == region {
+		return true
+	}
+
+	for _, part := range regionParts[region] {
+		if part == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package pindxru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	r := Validate(" 664001 ")
+	require.True(t, r.Valid)
+	require.Equal(t, "664001", r.Index)
+	require.Equal(t, 38, r.RegionCode)
+	require.Empty(t, r.Issues)
+
+	for _, index := range []string{"", "66400", "6640011", "66400a", "６６４００１"} {
+		r = Validate(index)
+		require.False(t, r.Valid, index)
+		require.Equal(t, []ValidationReason{ReasonFormat}, testReasons(r), index)
+	}
+
+	r = Validate("000001")
+	require.False(t, r.Valid)
+	require.Equal(t, []ValidationReason{ReasonUnknownPrefix}, testReasons(r))
+
+	d := NewDirectory(testReadZip(t, testPIndxZip, ReadPIndxZip))
+	r = Validate("664001", ValidateDirectory(d))
+	require.True(t, r.Valid)
+	require.Equal(t, "ИРКУТСК 1", r.PIndx.OpsName)
+
+	r = Validate("664002", ValidateDirectory(d), ValidateRegion("Иркутская область"))
+	require.False(t, r.Valid)
+	require.True(t, r.Has(ReasonNotFound))
+	require.Nil(t, r.PIndx)
+
+	r = Validate("664001", ValidateRegion("МОСКВА"))
+	require.Equal(t, []ValidationReason{ReasonRegionMismatch}, testReasons(r))
+	require.Contains(t, r.Issues[0].Message, "ИРКУТСКАЯ ОБЛАСТЬ")
+
+	r = Validate("664001", ValidateRegionCode(77))
+	require.True(t, r.Has(ReasonRegionMismatch))
+
+	r = Validate("664001", ValidateRegion("Иркутская губерния"))
+	require.Equal(t, []ValidationReason{ReasonUnknownRegion}, testReasons(r))
+
+	// автономный округ в составе области
+	r = Validate("628001", ValidateRegion("Тюменская область"), ValidateDirectory(d))
+	require.True(t, r.Valid)
+	require.Equal(t, 86, r.RegionCode)
+	require.False(t, Validate("625000", ValidateRegionCode(86)).Valid)
+	require.False(t, Validate("628001", ValidateRegionCode(89)).Valid)
+}
+
+func testReasons(r ValidationResult) (reasons []ValidationReason) {
+	reasons = []ValidationReason{}
+	for _, issue := range r.Issues {
+		reasons = append(reasons, issue.Reason)
+	}
+	return
+}
@@ -0,0 +1,22 @@
+package pindxru
+
+// verifyChecksum Возвращает контрольную сумму файла filename и сверяет ее с expected, если она указана.
+func verifyChecksum(u, filename, expected string) (sum string, err error) {
+	if sum, err = fileChecksum(filename); err != nil {
+		return
+	}
+
+	if expected != "" && expected != sum {
+		err = &ChecksumError{URL: u, Expected: expected, Actual: sum}
+	}
+	return
+}
+
+// verifyRecords Сверяет количество записей n с file.Records. Если количество в справочнике
+// не указано, то проверка не выполняется.
+func verifyRecords(file ReferenceFile, n int) (err error) {
+	if file.Records > 0 && file.Records != n {
+		err = &RecordCountError{URL: file.Url, Expected: file.Records, Actual: n}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package pindxru
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_verify(t *testing.T) {
+	c, rows := newTestClient(t)
+
+	sum, err := fileChecksum(filepath.Join(testdata, testPIndxZip))
+	require.Nil(t, err)
+
+	_, _, err = c.Indexes(rows, nil)
+	require.Nil(t, err)
+	require.Equal(t, sum, rows[2].Full.SHA256)
+
+	// количество записей не совпадает
+	invalid := append(ReferenceRows{}, rows...)
+	invalid[2].Full.Records = len(testPIndxRows) + 1
+	indexes, _, err := c.Indexes(invalid, nil)
+	var countErr *RecordCountError
+	require.ErrorAs(t, err, &countErr)
+	require.Equal(t, len(testPIndxRows)+1, countErr.Expected)
+	require.Equal(t, len(testPIndxRows), countErr.Actual)
+	require.Nil(t, indexes)
+
+	// проверка по заголовку до передачи записей в fn
+	var n int
+	_, err = c.EachIndex(context.Background(), invalid, func(PIndx) error {
+		n++
+		return nil
+	})
+	require.ErrorAs(t, err, &countErr)
+	require.Equal(t, 0, n)
+
+	// контрольная сумма не совпадает
+	invalid = append(ReferenceRows{}, rows...)
+	invalid[2].Full.SHA256 = "0000"
+	_, _, err = c.Indexes(invalid, nil)
+	var sumErr *ChecksumError
+	require.ErrorAs(t, err, &sumErr)
+	require.Equal(t, sum, sumErr.Actual)
+
+	// архив с другой контрольной суммой не сохраняется
+	zipFile := filepath.Join(t.TempDir(), testZipFile)
+	_, _, err = c.IndexesZip(invalid, zipFile, 0666, nil)
+	require.ErrorAs(t, err, &sumErr)
+	testRequireNoFiles(t, zipFile)
+
+	packages, err := rows.GetUpdatePackages(nil)
+	require.Nil(t, err)
+
+	_, err = c.GetPackageIndexes(&packages[0])
+	require.Nil(t, err)
+	sum, err = fileChecksum(filepath.Join(testdata, testNPIndxZip))
+	require.Nil(t, err)
+	require.Equal(t, sum, packages[0].SHA256)
+
+	packages[1].NumberRecords = 1
+	_, err = c.GetPackageIndexes(&packages[1])
+	require.ErrorAs(t, err, &countErr)
+	require.Nil(t, packages[1].Indexes)
+	require.Empty(t, packages[1].SHA256)
+
+	packages[2].SHA256 = "0000"
+	err = c.PackageZip(packages[2], zipFile, 0666)
+	require.ErrorAs(t, err, &sumErr)
+	testRequireNoFiles(t, zipFile)
+
+	err = c.PackageDbf(packages[2], filepath.Join(t.TempDir(), testDbfFile), 0666)
+	require.ErrorAs(t, err, &sumErr)
+}
+
+// testRequireNoFiles Проверяет, что нет ни файла, ни его незавершенной загрузки.
+func testRequireNoFiles(t *testing.T, filename string) {
+	for _, name := range []string{filename, filename + partSuffix, filename + validatorSuffix} {
+		_, err := os.Stat(name)
+		require.True(t, os.IsNotExist(err), name)
+	}
+}